@@ -2,7 +2,7 @@
 
 set -e
 
-pushd $GOPATH/src/github.com/kopwei/goovs/ > /dev/null
+pushd $GOPATH/src/github.com/rocksolidlabs/goovs/ > /dev/null
 echo "Restoring dependencies"
 godep restore
 popd > /dev/null 
@@ -4,7 +4,7 @@ from subprocess import call
 
 def main():
 	call(["/bin/bash", "/files/restore_dep.sh"])
-	call(["/usr/local/go/bin/go", "test", "github.com/kopwei/goovs" , "-v", "-cover"])
+	call(["/usr/local/go/bin/go", "test", "github.com/rocksolidlabs/goovs" , "-v", "-cover"])
 	
 
 if __name__ == "__main__":
@@ -4,7 +4,7 @@ import (
 	"log"
 	"os/exec"
 
-	"github.com/kopwei/goovs"
+	"github.com/rocksolidlabs/goovs"
 )
 
 func main() {
@@ -0,0 +1,117 @@
+// Package metrics provides a Prometheus collector for the goovs client
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rocksolidlabs/goovs"
+)
+
+const namespace = "goovs"
+
+// Collector records the goovs client activity as Prometheus metrics. It is
+// installed with goovs.SetInstrumentation and registered to a Prometheus
+// registry like any other collector
+type Collector struct {
+	transactionDuration *prometheus.HistogramVec
+	monitorRows         *prometheus.CounterVec
+	cacheSize           *prometheus.GaugeVec
+	disconnects         *prometheus.CounterVec
+	reconnects          *prometheus.CounterVec
+	warnings            *prometheus.CounterVec
+}
+
+var _ goovs.Instrumentation = &Collector{}
+var _ prometheus.Collector = &Collector{}
+
+// NewCollector creates the collector with all its metrics
+func NewCollector() *Collector {
+	return &Collector{
+		transactionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "transaction_duration_seconds",
+			Help:      "Duration of the ovsdb transactions by action and outcome.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"action", "outcome"}),
+		monitorRows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "monitor_update_rows_total",
+			Help:      "Number of rows received in monitor updates by table.",
+		}, []string{"table"}),
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cache_rows",
+			Help:      "Number of rows held in the cache by table.",
+		}, []string{"table"}),
+		disconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "disconnects_total",
+			Help:      "Number of sessions towards ovsdb-server which were lost.",
+		}, []string{"session"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconnects_total",
+			Help:      "Number of sessions towards ovsdb-server which were established again.",
+		}, []string{"session"}),
+		warnings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "warnings_total",
+			Help:      "Number of warnings raised by kind.",
+		}, []string{"kind"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	collector.transactionDuration.Describe(ch)
+	collector.monitorRows.Describe(ch)
+	collector.cacheSize.Describe(ch)
+	collector.disconnects.Describe(ch)
+	collector.reconnects.Describe(ch)
+	collector.warnings.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	collector.transactionDuration.Collect(ch)
+	collector.monitorRows.Collect(ch)
+	collector.cacheSize.Collect(ch)
+	collector.disconnects.Collect(ch)
+	collector.reconnects.Collect(ch)
+	collector.warnings.Collect(ch)
+}
+
+// TransactionDone implements goovs.Instrumentation
+func (collector *Collector) TransactionDone(action string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	collector.transactionDuration.WithLabelValues(action, outcome).Observe(duration.Seconds())
+}
+
+// MonitorRowsUpdated implements goovs.Instrumentation
+func (collector *Collector) MonitorRowsUpdated(table string, rows int) {
+	collector.monitorRows.WithLabelValues(table).Add(float64(rows))
+}
+
+// CacheSizeChanged implements goovs.Instrumentation
+func (collector *Collector) CacheSizeChanged(cache string, size int) {
+	collector.cacheSize.WithLabelValues(cache).Set(float64(size))
+}
+
+// Disconnected implements goovs.Instrumentation
+func (collector *Collector) Disconnected(session string) {
+	collector.disconnects.WithLabelValues(session).Inc()
+}
+
+// Reconnected implements goovs.Instrumentation
+func (collector *Collector) Reconnected(session string) {
+	collector.reconnects.WithLabelValues(session).Inc()
+}
+
+// Warning implements goovs.Instrumentation
+func (collector *Collector) Warning(kind, message string) {
+	collector.warnings.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+	collector.TransactionDone("create bridge", time.Millisecond, nil)
+	collector.TransactionDone("delete port", time.Millisecond, fmt.Errorf("failed"))
+	collector.MonitorRowsUpdated("Port", 3)
+	collector.CacheSizeChanged("Port", 3)
+	collector.Reconnected("lock")
+	collector.Warning("interface_type", "port p1 on bridge br0: the dpdk interface requires the netdev datapath")
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	for _, name := range []string{"goovs_transaction_duration_seconds", "goovs_monitor_update_rows_total", "goovs_cache_rows", "goovs_reconnects_total", "goovs_warnings_total"} {
+		if !names[name] {
+			t.Fatalf("The metric %s is not collected", name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package goovs
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const autoAttachTableName = "AutoAttach"
+
+const maxAutoAttachISID = 16777215
+
+// OvsAutoAttach is the structure represents an AutoAttach row, the mappings
+// map the I-SIDs to the VLANs
+type OvsAutoAttach struct {
+	UUID              string      `json:"_uuid"`
+	SystemName        string      `json:"system_name"`
+	SystemDescription string      `json:"system_description"`
+	Mappings          map[int]int `json:"mappings"`
+}
+
+// AutoAttachSpec describes the Auto-Attach settings of a bridge, Mappings
+// maps the I-SIDs to the VLANs
+type AutoAttachSpec struct {
+	SystemName        string
+	SystemDescription string
+	Mappings          map[int]int
+}
+
+// ReadFromDBRow is used to initialize the object from a row
+func (autoAttach *OvsAutoAttach) ReadFromDBRow(row *libovsdb.Row) error {
+	autoAttach.Mappings = make(map[int]int)
+	for field, value := range row.Fields {
+		switch field {
+		case "system_name":
+			autoAttach.SystemName = value.(string)
+		case "system_description":
+			autoAttach.SystemDescription = value.(string)
+		case "mappings":
+			if mappings, ok := value.(libovsdb.OvsMap); ok {
+				for key, elem := range mappings.GoMap {
+					isid, okISID := intFromValue(key)
+					vlan, okVlan := intFromValue(elem)
+					if okISID && okVlan {
+						autoAttach.Mappings[isid] = vlan
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SetBridgeAutoAttach enables Auto-Attach on a bridge, replacing its current
+// settings. LLDP must be enabled on the uplinks, see SetInterfaceLLDP
+func (client *ovsClient) SetBridgeAutoAttach(brname string, spec AutoAttachSpec) error {
+	if err := validateAutoAttachMappings(spec.Mappings); err != nil {
+		return err
+	}
+	autoAttach := make(map[string]interface{})
+	autoAttach["system_name"] = spec.SystemName
+	autoAttach["system_description"] = spec.SystemDescription
+	if len(spec.Mappings) != 0 {
+		autoAttach["mappings"], _ = libovsdb.NewOvsMap(spec.Mappings)
+	}
+	return client.setBridgeReference(brname, "auto_attach", autoAttachTableName, autoAttach, "set bridge auto attach")
+}
+
+// ClearBridgeAutoAttach disables Auto-Attach on a bridge
+func (client *ovsClient) ClearBridgeAutoAttach(brname string) error {
+	return client.setBridgeReference(brname, "auto_attach", autoAttachTableName, nil, "clear bridge auto attach")
+}
+
+// GetBridgeAutoAttach returns the Auto-Attach settings of a bridge, nil if
+// Auto-Attach is disabled
+func (client *ovsClient) GetBridgeAutoAttach(brname string) (*OvsAutoAttach, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return nil, err
+	}
+	autoAttachCacheUpdateLock.RLock()
+	defer autoAttachCacheUpdateLock.RUnlock()
+	autoAttach, ok := client.autoAttachCache[bridge.AutoAttachUUID]
+	if !ok {
+		return nil, nil
+	}
+	autoAttachObj := *autoAttach
+	return &autoAttachObj, nil
+}
+
+// AddAutoAttachMapping maps the I-SID to the VLAN on a bridge where
+// Auto-Attach is enabled, an existing mapping of the I-SID is replaced
+func (client *ovsClient) AddAutoAttachMapping(brname string, isid, vlan int) error {
+	if err := validateAutoAttachMappings(map[int]int{isid: vlan}); err != nil {
+		return err
+	}
+	keySet, _ := libovsdb.NewOvsSet([]int{isid})
+	mapping, _ := libovsdb.NewOvsMap(map[int]int{isid: vlan})
+	return client.mutateAutoAttachMappings(brname, []interface{}{
+		libovsdb.NewMutation("mappings", deleteOperation, keySet),
+		libovsdb.NewMutation("mappings", insertOperation, mapping),
+	}, "add auto attach mapping")
+}
+
+// DeleteAutoAttachMapping removes the mapping of the I-SID on a bridge
+func (client *ovsClient) DeleteAutoAttachMapping(brname string, isid int) error {
+	keySet, _ := libovsdb.NewOvsSet([]int{isid})
+	return client.mutateAutoAttachMappings(brname, []interface{}{
+		libovsdb.NewMutation("mappings", deleteOperation, keySet),
+	}, "delete auto attach mapping")
+}
+
+// SetInterfaceLLDP enables or disables LLDP on an interface, which
+// Auto-Attach uses to advertise the mappings
+func (client *ovsClient) SetInterfaceLLDP(intfname string, enable bool) error {
+	intfUpdateLock.Lock()
+	defer intfUpdateLock.Unlock()
+	if _, err := client.getInterfaceByName(intfname); err != nil {
+		return err
+	}
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     interfaceTableName,
+		Mutations: newMapUpdateMutations("lldp", map[string]string{"enable": strconv.FormatBool(enable)}),
+		Where:     []interface{}{libovsdb.NewCondition("name", "==", intfname)},
+	}
+	return client.transact([]libovsdb.Operation{mutateOp}, "set interface lldp")
+}
+
+func (client *ovsClient) mutateAutoAttachMappings(brname string, mutations []interface{}, action string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return err
+	}
+	if bridge.AutoAttachUUID == "" {
+		return fmt.Errorf("The auto attach is not enabled on bridge %s", brname)
+	}
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     autoAttachTableName,
+		Mutations: mutations,
+		Where:     []interface{}{libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: bridge.AutoAttachUUID})},
+	}
+	return client.transact([]libovsdb.Operation{mutateOp}, action)
+}
+
+func validateAutoAttachMappings(mappings map[int]int) error {
+	for isid, vlan := range mappings {
+		if isid < 0 || isid > maxAutoAttachISID {
+			return fmt.Errorf("The I-SID %d is invalid, it should be within [0, %d]", isid, maxAutoAttachISID)
+		}
+		if err := validateVlanTag(vlan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
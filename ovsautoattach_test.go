@@ -0,0 +1,46 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestAutoAttachReadFromDBRow(t *testing.T) {
+	mappings, _ := libovsdb.NewOvsMap(map[float64]float64{100: 10, 200: 20})
+	autoAttach := &OvsAutoAttach{}
+	err := autoAttach.ReadFromDBRow(&libovsdb.Row{Fields: map[string]interface{}{
+		"system_name": "sw1",
+		"mappings":    *mappings,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if autoAttach.SystemName != "sw1" || len(autoAttach.Mappings) != 2 || autoAttach.Mappings[200] != 20 {
+		t.Fatalf("The auto attach %+v is incorrect", autoAttach)
+	}
+}
+
+func TestValidateAutoAttachMappings(t *testing.T) {
+	if err := validateAutoAttachMappings(map[int]int{16777215: 4095}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateAutoAttachMappings(map[int]int{16777216: 10}); err == nil {
+		t.Fatal("The I-SID 16777216 should be invalid")
+	}
+	if err := validateAutoAttachMappings(map[int]int{100: 4096}); err == nil {
+		t.Fatal("The vlan 4096 should be invalid")
+	}
+}
+
+func TestSetBridgeAutoAttach(t *testing.T) {
+	// TODO
+}
+
+func TestAddAutoAttachMapping(t *testing.T) {
+	// TODO
+}
+
+func TestSetInterfaceLLDP(t *testing.T) {
+	// TODO
+}
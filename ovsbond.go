@@ -0,0 +1,267 @@
+package goovs
+
+import (
+	"fmt"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	// BondModeActiveBackup sends the traffic through a single member
+	BondModeActiveBackup = "active-backup"
+	// BondModeBalanceSLB balances the traffic by source MAC and VLAN
+	BondModeBalanceSLB = "balance-slb"
+	// BondModeBalanceTCP balances the traffic by L3 and L4 headers, it
+	// requires LACP
+	BondModeBalanceTCP = "balance-tcp"
+)
+
+const (
+	// LACPActive initiates the LACP negotiation
+	LACPActive = "active"
+	// LACPPassive answers the LACP negotiation of the partner
+	LACPPassive = "passive"
+	// LACPOff disables LACP
+	LACPOff = "off"
+)
+
+// BondSpec describes the settings of the bond port inserted by CreateBond.
+// The delays and the rebalance interval are in milliseconds, a nil interval
+// isn't written while 0 disables rebalancing. FallbackAB makes an LACP bond
+// fall back to active-backup when the partner doesn't answer
+type BondSpec struct {
+	Mode              string
+	LACP              string
+	Updelay           int
+	Downdelay         int
+	FallbackAB        bool
+	RebalanceInterval *int
+}
+
+// BondStatus is the status of a bond port reported by ovs-vswitchd
+type BondStatus struct {
+	Mode string
+	LACP string
+	// ActiveMember is the name of the member in use by an active-backup
+	// bond, ActiveMemberMAC its MAC address
+	ActiveMember    string
+	ActiveMemberMAC string
+	Members         []BondMemberStatus
+}
+
+// BondMemberStatus is the status of a bond member, LACPCurrent is nil if
+// LACP isn't in use
+type BondMemberStatus struct {
+	Name        string
+	LACPCurrent *bool
+}
+
+// CreateBond creates a port bonding the interfaces, at least two interfaces
+// are required
+func (client *ovsClient) CreateBond(brname, portname string, ifaces []string, spec BondSpec) error {
+	if len(ifaces) < 2 {
+		return fmt.Errorf("The bond %s needs at least two interfaces", portname)
+	}
+	bond, otherConfig, err := newBondColumns(spec)
+	if err != nil {
+		return err
+	}
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	// the ports of a fake bridge are held by its parent and tagged with its
+	// vlan
+	brname, vlantag, err := client.resolvePortBridge(brname, 0)
+	if err != nil {
+		return err
+	}
+	if vlantag != 0 {
+		bond["tag"] = vlantag
+	}
+	if _, err = client.getPortByName(portname); err == nil {
+		return fmt.Errorf("The port %s already exists", portname)
+	}
+
+	namedPortUUID := "goport"
+	var operations []libovsdb.Operation
+	intfUUIDs := make([]libovsdb.UUID, 0, len(ifaces))
+	for index, iface := range ifaces {
+		if _, err = client.getInterfaceByName(iface); err == nil {
+			return fmt.Errorf("The interface %s already exists", iface)
+		}
+		client.warnInterfaceType(brname, iface, "system")
+		namedInterfaceUUID := fmt.Sprintf("gointerface%d", index)
+		intf := make(map[string]interface{})
+		intf["name"] = iface
+		intf["type"] = `system`
+		operations = append(operations, libovsdb.Operation{
+			Op:       insertOperation,
+			Table:    interfaceTableName,
+			Row:      intf,
+			UUIDName: namedInterfaceUUID,
+		})
+		intfUUIDs = append(intfUUIDs, libovsdb.UUID{GoUUID: namedInterfaceUUID})
+	}
+
+	// port row to insert
+	port := bond
+	port["name"] = portname
+	port["interfaces"], _ = libovsdb.NewOvsSet(intfUUIDs)
+	if len(otherConfig) != 0 {
+		port["other_config"], _ = libovsdb.NewOvsMap(otherConfig)
+	}
+	operations = append(operations, libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    portTableName,
+		Row:      port,
+		UUIDName: namedPortUUID,
+	})
+
+	// Inserting a Port row in Port table requires mutating the Bridge table
+	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: namedPortUUID}}
+	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
+	mutation := libovsdb.NewMutation("ports", insertOperation, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", brname)
+	operations = append(operations, libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     bridgeTableName,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	})
+	return client.transact(operations, "create bond")
+}
+
+// AddBondMember adds an interface to a bond port
+func (client *ovsClient) AddBondMember(portname, iface string) error {
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return err
+	}
+	if !isBond(port) {
+		return fmt.Errorf("The port %s is not a bond", portname)
+	}
+	if _, err = client.getInterfaceByName(iface); err == nil {
+		return fmt.Errorf("The interface %s already exists", iface)
+	}
+	if brname, err := client.getBridgeNameByPortUUID(port.UUID); err == nil {
+		client.warnInterfaceType(brname, iface, "system")
+	}
+	intf := make(map[string]interface{})
+	intf["name"] = iface
+	intf["type"] = `system`
+	return client.addInterfaceOnPort(portname, intf)
+}
+
+// RemoveBondMember removes an interface from a bond port, the last
+// interface of the port can't be removed
+func (client *ovsClient) RemoveBondMember(portname, iface string) error {
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return err
+	}
+	if !isBond(port) {
+		return fmt.Errorf("The port %s is not a bond", portname)
+	}
+	intf, err := client.getInterfaceByName(iface)
+	if err != nil {
+		return err
+	}
+	member := false
+	for _, uuid := range port.IntfUUIDs {
+		if uuid == intf.UUID {
+			member = true
+		}
+	}
+	if !member {
+		return fmt.Errorf("The interface %s is not a member of bond %s", iface, portname)
+	}
+	if len(port.IntfUUIDs) == 1 {
+		return fmt.Errorf("The interface %s is the last member of bond %s", iface, portname)
+	}
+	return client.RemoveInterfaceFromPort(portname, intf.UUID)
+}
+
+// GetBondStatus returns the status of a bond port
+func (client *ovsClient) GetBondStatus(portname string) (BondStatus, error) {
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return BondStatus{}, err
+	}
+	status := BondStatus{
+		Mode:            port.BondMode,
+		LACP:            port.LACP,
+		ActiveMemberMAC: port.BondActiveSlave,
+		Members:         make([]BondMemberStatus, 0, len(port.IntfUUIDs)),
+	}
+	intfCacheUpdateLock.RLock()
+	defer intfCacheUpdateLock.RUnlock()
+	for _, uuid := range port.IntfUUIDs {
+		intf, ok := client.interfaceCache[uuid]
+		if !ok {
+			continue
+		}
+		if status.ActiveMemberMAC != "" && intf.MACInUse == status.ActiveMemberMAC {
+			status.ActiveMember = intf.Name
+		}
+		status.Members = append(status.Members, BondMemberStatus{Name: intf.Name, LACPCurrent: intf.LACPCurrent})
+	}
+	return status, nil
+}
+
+// isBond tells if the port is a bond, i.e. it has several interfaces or
+// the bond settings
+func isBond(port *OvsPort) bool {
+	return len(port.IntfUUIDs) > 1 || port.BondMode != "" || port.LACP != ""
+}
+
+// newBondColumns validates the spec and returns the Port columns and the
+// other_config keys to set
+func newBondColumns(spec BondSpec) (map[string]interface{}, map[string]string, error) {
+	switch spec.Mode {
+	case "", BondModeActiveBackup, BondModeBalanceSLB, BondModeBalanceTCP:
+	default:
+		return nil, nil, fmt.Errorf("The bond mode %s is invalid", spec.Mode)
+	}
+	switch spec.LACP {
+	case "", LACPActive, LACPPassive, LACPOff:
+	default:
+		return nil, nil, fmt.Errorf("The lacp mode %s is invalid, it should be %s, %s or %s", spec.LACP, LACPActive, LACPPassive, LACPOff)
+	}
+	lacpEnabled := spec.LACP == LACPActive || spec.LACP == LACPPassive
+	if spec.Mode == BondModeBalanceTCP && !lacpEnabled {
+		return nil, nil, fmt.Errorf("The %s bond mode requires LACP", BondModeBalanceTCP)
+	}
+	if spec.FallbackAB && !lacpEnabled {
+		return nil, nil, fmt.Errorf("The lacp-fallback-ab setting requires LACP")
+	}
+	if spec.Updelay < 0 || spec.Downdelay < 0 {
+		return nil, nil, fmt.Errorf("The bond delays %d and %d are invalid", spec.Updelay, spec.Downdelay)
+	}
+	otherConfig, err := newIntConfig([]intSetting{
+		{"bond-rebalance-interval", spec.RebalanceInterval, 0, 10000},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	otherConfig = setConfigKeys(otherConfig)
+	if spec.FallbackAB {
+		otherConfig["lacp-fallback-ab"] = "true"
+	}
+	bond := make(map[string]interface{})
+	if spec.Mode != "" {
+		bond["bond_mode"] = spec.Mode
+	}
+	if spec.LACP != "" {
+		bond["lacp"] = spec.LACP
+	}
+	if spec.Updelay > 0 {
+		bond["bond_updelay"] = spec.Updelay
+	}
+	if spec.Downdelay > 0 {
+		bond["bond_downdelay"] = spec.Downdelay
+	}
+	return bond, otherConfig, nil
+}
@@ -0,0 +1,183 @@
+package goovs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBondColumns(t *testing.T) {
+	interval, disabled, tooLong := 5000, 0, 20000
+	bond, otherConfig, err := newBondColumns(BondSpec{Mode: BondModeBalanceTCP, LACP: LACPActive, Updelay: 200, FallbackAB: true, RebalanceInterval: &interval})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bond["bond_mode"] != BondModeBalanceTCP || bond["lacp"] != LACPActive || bond["bond_updelay"] != 200 {
+		t.Fatalf("The bond columns %v are incorrect", bond)
+	}
+	if _, ok := bond["bond_downdelay"]; ok {
+		t.Fatal("The bond_downdelay should be left to the ovs default")
+	}
+	if otherConfig["lacp-fallback-ab"] != "true" || otherConfig["bond-rebalance-interval"] != "5000" {
+		t.Fatalf("The bond other_config %v is incorrect", otherConfig)
+	}
+	if _, otherConfig, err = newBondColumns(BondSpec{RebalanceInterval: &disabled}); err != nil || otherConfig["bond-rebalance-interval"] != "0" {
+		t.Fatalf("A zero rebalance interval should disable rebalancing, got %v", otherConfig)
+	}
+	if _, otherConfig, err = newBondColumns(BondSpec{}); err != nil || len(otherConfig) != 0 {
+		t.Fatalf("A nil rebalance interval should not be written, got %v", otherConfig)
+	}
+	invalid := []BondSpec{
+		{Mode: "balance-xor"},
+		{LACP: "on"},
+		{Mode: BondModeBalanceTCP},
+		{Mode: BondModeActiveBackup, FallbackAB: true},
+		{Updelay: -1},
+		{RebalanceInterval: &tooLong},
+	}
+	for _, spec := range invalid {
+		if _, _, err = newBondColumns(spec); err == nil {
+			t.Fatalf("The bond spec %+v should be invalid", spec)
+		}
+	}
+}
+
+func TestGetBondStatus(t *testing.T) {
+	current := true
+	testClient := &ovsClient{
+		portCache: map[string]*OvsPort{
+			"p1": {UUID: "p1", Name: "bond0", IntfUUIDs: []string{"i1", "i2"}, BondMode: BondModeActiveBackup, BondActiveSlave: "02:00:00:00:00:02"},
+		},
+		interfaceCache: map[string]*OvsInterface{
+			"i1": {UUID: "i1", Name: "eth0", MACInUse: "02:00:00:00:00:01"},
+			"i2": {UUID: "i2", Name: "eth1", MACInUse: "02:00:00:00:00:02", LACPCurrent: &current},
+		},
+	}
+	status, err := testClient.GetBondStatus("bond0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ActiveMember != "eth1" || len(status.Members) != 2 {
+		t.Fatalf("The bond status %+v is incorrect", status)
+	}
+}
+
+func newBondTestClient() *ovsClient {
+	return &ovsClient{
+		bridgeCache: map[string]*OvsBridge{
+			"br0": {UUID: "br0", Name: "br0", PortUUIDs: []string{"p0", "p1", "p2", "p3"}},
+		},
+		portCache: map[string]*OvsPort{
+			"p0": {UUID: "p0", Name: "br0"},
+			"p1": {UUID: "p1", Name: "bond0", IntfUUIDs: []string{"i1", "i2"}, BondMode: BondModeActiveBackup},
+			"p2": {UUID: "p2", Name: "vm0", IntfUUIDs: []string{"i3"}},
+			"p3": {UUID: "p3", Name: "bond1", IntfUUIDs: []string{"i4"}, LACP: LACPActive},
+		},
+		interfaceCache: map[string]*OvsInterface{
+			"i1": {UUID: "i1", Name: "eth0"},
+			"i2": {UUID: "i2", Name: "eth1"},
+			"i3": {UUID: "i3", Name: "vm0"},
+			"i4": {UUID: "i4", Name: "eth4"},
+		},
+	}
+}
+
+// receiveOperations returns the operations of the next transaction
+func receiveOperations(t *testing.T, transactions <-chan []map[string]interface{}) []map[string]interface{} {
+	select {
+	case operations := <-transactions:
+		return operations
+	case <-time.After(time.Second):
+		t.Fatal("No transaction is sent")
+	}
+	return nil
+}
+
+func TestCreateBond(t *testing.T) {
+	testClient := newBondTestClient()
+	transactions, release := newFakeTransactClient(t, testClient)
+	defer release()
+
+	invalid := []struct {
+		brname, portname string
+		ifaces           []string
+		spec             BondSpec
+	}{
+		{"br0", "bond2", []string{"eth5"}, BondSpec{}},
+		{"br0", "bond2", []string{"eth5", "eth6"}, BondSpec{Mode: "balance-xor"}},
+		{"br9", "bond2", []string{"eth5", "eth6"}, BondSpec{}},
+		{"br0", "bond0", []string{"eth5", "eth6"}, BondSpec{}},
+		{"br0", "bond2", []string{"eth0", "eth6"}, BondSpec{}},
+	}
+	for _, bond := range invalid {
+		if err := testClient.CreateBond(bond.brname, bond.portname, bond.ifaces, bond.spec); err == nil {
+			t.Fatalf("The bond %+v should be rejected", bond)
+		}
+	}
+
+	if err := testClient.CreateBond("br0", "bond2", []string{"eth5", "eth6"}, BondSpec{Mode: BondModeActiveBackup}); err != nil {
+		t.Fatal(err)
+	}
+	operations := receiveOperations(t, transactions)
+	if len(operations) != 4 || operations[0]["table"] != interfaceTableName || operations[1]["table"] != interfaceTableName {
+		t.Fatalf("The operations %v are incorrect", operations)
+	}
+	port, _ := operations[2]["row"].(map[string]interface{})
+	if operations[2]["table"] != portTableName || port["name"] != "bond2" || port["bond_mode"] != BondModeActiveBackup {
+		t.Fatalf("The bond port %v is incorrect", operations[2])
+	}
+	if operations[3]["table"] != bridgeTableName {
+		t.Fatalf("The bridge should be mutated, got %v", operations[3])
+	}
+}
+
+func TestAddBondMember(t *testing.T) {
+	testClient := newBondTestClient()
+	transactions, release := newFakeTransactClient(t, testClient)
+	defer release()
+
+	if err := testClient.AddBondMember("bond9", "eth5"); err == nil {
+		t.Fatal("The unknown port should be rejected")
+	}
+	if err := testClient.AddBondMember("vm0", "eth5"); err == nil {
+		t.Fatal("The port vm0 is not a bond")
+	}
+	if err := testClient.AddBondMember("bond0", "eth1"); err == nil {
+		t.Fatal("The existing interface should be rejected")
+	}
+
+	if err := testClient.AddBondMember("bond0", "eth5"); err != nil {
+		t.Fatal(err)
+	}
+	operations := receiveOperations(t, transactions)
+	intf, _ := operations[0]["row"].(map[string]interface{})
+	if len(operations) != 2 || intf["name"] != "eth5" || operations[1]["table"] != portTableName {
+		t.Fatalf("The operations %v are incorrect", operations)
+	}
+}
+
+func TestRemoveBondMember(t *testing.T) {
+	testClient := newBondTestClient()
+	transactions, release := newFakeTransactClient(t, testClient)
+	defer release()
+
+	if err := testClient.RemoveBondMember("vm0", "vm0"); err == nil {
+		t.Fatal("The port vm0 is not a bond")
+	}
+	if err := testClient.RemoveBondMember("bond0", "eth9"); err == nil {
+		t.Fatal("The unknown interface should be rejected")
+	}
+	if err := testClient.RemoveBondMember("bond0", "eth4"); err == nil {
+		t.Fatal("The interface eth4 is not a member of bond0")
+	}
+	if err := testClient.RemoveBondMember("bond1", "eth4"); err == nil {
+		t.Fatal("The last member should not be removed")
+	}
+
+	if err := testClient.RemoveBondMember("bond0", "eth1"); err != nil {
+		t.Fatal(err)
+	}
+	operations := receiveOperations(t, transactions)
+	if len(operations) != 2 || operations[0]["op"] != deleteOperation || operations[0]["table"] != interfaceTableName {
+		t.Fatalf("The operations %v are incorrect", operations)
+	}
+}
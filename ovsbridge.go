@@ -2,17 +2,51 @@ package goovs
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/rocksolidlabs/libovsdb"
 )
 
+const (
+	// FailModeStandalone makes the bridge act as a learning switch when the
+	// controllers are unreachable
+	FailModeStandalone = "standalone"
+	// FailModeSecure keeps the flows untouched when the controllers are
+	// unreachable
+	FailModeSecure = "secure"
+)
+
+// OpenFlowProtocols lists the protocol names allowed in Bridge.protocols
+var OpenFlowProtocols = []string{"OpenFlow10", "OpenFlow11", "OpenFlow12", "OpenFlow13", "OpenFlow14", "OpenFlow15"}
+
 // OvsBridge is the structure represents the ovs bridge
 type OvsBridge struct {
-	UUID       string   `json:"_uuid"`
-	Controller string   `json:"controller"`
-	Name       string   `json:"name"`
-	PortUUIDs  []string `json:"ports"`
-	DatapathID string   `json:"datapath_id"`
+	UUID                string            `json:"_uuid"`
+	Controller          string            `json:"controller"`
+	ControllerUUIDs     []string          `json:"controllers"`
+	Name                string            `json:"name"`
+	PortUUIDs           []string          `json:"ports"`
+	MirrorUUIDs         []string          `json:"mirrors"`
+	NetFlowUUID         string            `json:"netflow"`
+	SFlowUUID           string            `json:"sflow"`
+	IPFIXUUID           string            `json:"ipfix"`
+	AutoAttachUUID      string            `json:"auto_attach"`
+	FlowTableUUIDs      map[int]string    `json:"flow_tables"`
+	FloodVLANs          []int             `json:"flood_vlans"`
+	MACAgingTime        int               `json:"mac-aging-time"`
+	MACTableSize        int               `json:"mac-table-size"`
+	DatapathID          string            `json:"datapath_id"`
+	DatapathType        string            `json:"datapath_type"`
+	DatapathVersion     string            `json:"datapath_version"`
+	FailMode            string            `json:"fail_mode"`
+	Protocols           []string          `json:"protocols"`
+	STPEnable           bool              `json:"stp_enable"`
+	RSTPEnable          bool              `json:"rstp_enable"`
+	STPStatus           BridgeSTPStatus   `json:"status"`
+	RSTPStatus          BridgeRSTPStatus  `json:"rstp_status"`
+	McastSnoopingEnable bool              `json:"mcast_snooping_enable"`
+	OtherConfig         map[string]string `json:"other_config"`
 }
 
 // ReadFromDBRow is used to initialize the object from a row
@@ -20,15 +54,73 @@ func (bridge *OvsBridge) ReadFromDBRow(row *libovsdb.Row) error {
 	if bridge.PortUUIDs == nil {
 		bridge.PortUUIDs = make([]string, 0)
 	}
+	if bridge.ControllerUUIDs == nil {
+		bridge.ControllerUUIDs = make([]string, 0)
+	}
+	if bridge.OtherConfig == nil {
+		bridge.OtherConfig = make(map[string]string)
+	}
+	if bridge.FlowTableUUIDs == nil {
+		bridge.FlowTableUUIDs = make(map[int]string)
+	}
 	for field, value := range row.Fields {
 		switch field {
 		case "name":
 			bridge.Name = value.(string)
 		case "datapath_id":
-			switch value.(type) {
-			case string:
-				bridge.DatapathID = value.(string)
+			// the optional column is either a string or a set of up to one
+			if dpids := stringsFromValue(value); len(dpids) == 1 {
+				bridge.DatapathID = dpids[0]
+			} else {
+				bridge.DatapathID = ""
+			}
+		case "datapath_version":
+			bridge.DatapathVersion = value.(string)
+		case "controller":
+			bridge.ControllerUUIDs = uuidsFromValue(value)
+		case "mirrors":
+			bridge.MirrorUUIDs = uuidsFromValue(value)
+		case "netflow":
+			bridge.NetFlowUUID = optionalUUIDFromValue(value)
+		case "sflow":
+			bridge.SFlowUUID = optionalUUIDFromValue(value)
+		case "ipfix":
+			bridge.IPFIXUUID = optionalUUIDFromValue(value)
+		case "auto_attach":
+			bridge.AutoAttachUUID = optionalUUIDFromValue(value)
+		case "flood_vlans":
+			bridge.FloodVLANs = intsFromValue(value)
+		case "flow_tables":
+			bridge.FlowTableUUIDs = make(map[int]string)
+			if tables, ok := value.(libovsdb.OvsMap); ok {
+				for key, elem := range tables.GoMap {
+					tableID, okID := intFromValue(key)
+					uuid, okUUID := elem.(libovsdb.UUID)
+					if okID && okUUID {
+						bridge.FlowTableUUIDs[tableID] = uuid.GoUUID
+					}
+				}
 			}
+		case "datapath_type":
+			bridge.DatapathType = value.(string)
+		case "fail_mode":
+			bridge.FailMode, _ = stringFromValue(value)
+		case "protocols":
+			bridge.Protocols = stringsFromValue(value)
+		case "stp_enable":
+			bridge.STPEnable = value.(bool)
+		case "rstp_enable":
+			bridge.RSTPEnable = value.(bool)
+		case "status":
+			bridge.STPStatus.readFromMap(mapFromValue(value))
+		case "rstp_status":
+			bridge.RSTPStatus.readFromMap(mapFromValue(value))
+		case "mcast_snooping_enable":
+			bridge.McastSnoopingEnable = value.(bool)
+		case "other_config":
+			bridge.OtherConfig = mapFromValue(value)
+			bridge.MACAgingTime, _ = strconv.Atoi(bridge.OtherConfig["mac-aging-time"])
+			bridge.MACTableSize, _ = strconv.Atoi(bridge.OtherConfig["mac-table-size"])
 		case "ports":
 			switch value.(type) {
 			case libovsdb.UUID:
@@ -43,16 +135,73 @@ func (bridge *OvsBridge) ReadFromDBRow(row *libovsdb.Row) error {
 	return nil
 }
 
+// BridgeSpec describes the settings of a bridge. Empty fields are left to
+// their ovsdb default when creating a bridge, and unchanged when updating it
+type BridgeSpec struct {
+	Name         string
+	DatapathType string
+	FailMode     string
+	Protocols    []string
+	// DatapathID, HWAddr and DisableInBand are stored in other_config
+	DatapathID    string
+	HWAddr        string
+	DisableInBand bool
+	OtherConfig   map[string]string
+	ExternalIDs   map[string]string
+	// MTURequest is the mtu requested for the bridge internal interface
+	MTURequest int
+}
+
+// otherConfig merges the typed other_config settings into the map
+func (spec *BridgeSpec) otherConfig() map[string]string {
+	config := make(map[string]string)
+	for key, value := range spec.OtherConfig {
+		config[key] = value
+	}
+	if spec.DatapathID != "" {
+		config["datapath-id"] = spec.DatapathID
+	}
+	if spec.HWAddr != "" {
+		config["hwaddr"] = spec.HWAddr
+	}
+	if spec.DisableInBand {
+		config["disable-in-band"] = "true"
+	}
+	return config
+}
+
 // CreateBridge is used to create a ovs bridge
 func (client *ovsClient) CreateBridge(brname string) error {
+	return client.CreateBridgeWithSpec(BridgeSpec{Name: brname})
+}
+
+// CreateBridgeWithSpec creates a ovs bridge with the given settings applied
+// in the same transaction which inserts the bridge
+func (client *ovsClient) CreateBridgeWithSpec(spec BridgeSpec) error {
 	bridgeUpdateLock.Lock()
 	defer bridgeUpdateLock.Unlock()
-	bridgeExists, err := client.BridgeExists(brname)
+	brname := spec.Name
+	bridgeExists, err := client.bridgeRowExists(brname)
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve the bridge info")
 	} else if bridgeExists {
 		return nil
 	}
+	if _, _, err = client.getFakeBridge(brname); err == nil {
+		return fmt.Errorf("The fake bridge %s already exists", brname)
+	}
+	if err = client.checkDatapathSupported(spec.DatapathType); err != nil {
+		return err
+	}
+	if err = validateFailMode(spec.FailMode); err != nil {
+		return err
+	}
+	if err = validateProtocols(spec.Protocols); err != nil {
+		return err
+	}
+	if err = client.validateIdentity(spec); err != nil {
+		return err
+	}
 
 	namedBridgeUUID := "gobridge"
 	namedPortUUID := "goport"
@@ -62,10 +211,13 @@ func (client *ovsClient) CreateBridge(brname string) error {
 	intf := make(map[string]interface{})
 	intf["name"] = brname
 	intf["type"] = `internal`
+	if spec.MTURequest > 0 {
+		intf["mtu_request"] = spec.MTURequest
+	}
 
 	insertInterfaceOp := libovsdb.Operation{
-		Op:       "insert",
-		Table:    "Interface",
+		Op:       insertOperation,
+		Table:    interfaceTableName,
 		Row:      intf,
 		UUIDName: namedInterfaceUUID,
 	}
@@ -87,6 +239,21 @@ func (client *ovsClient) CreateBridge(brname string) error {
 	bridge["name"] = brname
 	bridge["stp_enable"] = false
 	bridge["ports"] = libovsdb.UUID{GoUUID: namedPortUUID}
+	if spec.DatapathType != "" {
+		bridge["datapath_type"] = spec.DatapathType
+	}
+	if spec.FailMode != "" {
+		bridge["fail_mode"] = spec.FailMode
+	}
+	if len(spec.Protocols) != 0 {
+		bridge["protocols"], _ = libovsdb.NewOvsSet(spec.Protocols)
+	}
+	if otherConfig := spec.otherConfig(); len(otherConfig) != 0 {
+		bridge["other_config"], _ = libovsdb.NewOvsMap(otherConfig)
+	}
+	if len(spec.ExternalIDs) != 0 {
+		bridge["external_ids"], _ = libovsdb.NewOvsMap(spec.ExternalIDs)
+	}
 
 	// simple insert operation
 	insertBridgeOp := libovsdb.Operation{
@@ -114,21 +281,52 @@ func (client *ovsClient) CreateBridge(brname string) error {
 	return client.transact(operations, "create bridge")
 }
 
-// DeleteBridge is used to delete a ovs bridge
+// BridgeDeleteReport lists the rows removed along with a bridge, the rows
+// are given by name where they have one
+type BridgeDeleteReport struct {
+	Bridge                  string
+	Ports                   []string
+	Interfaces              []string
+	Controllers             []string
+	Mirrors                 []string
+	NetFlow                 bool
+	SFlow                   bool
+	IPFIX                   bool
+	AutoAttach              bool
+	FlowTables              []int
+	FlowSampleCollectorSets []int
+}
+
+// DeleteBridge is used to delete a ovs bridge along with the rows it owns
 func (client *ovsClient) DeleteBridge(brname string) error {
+	_, err := client.DeleteBridgeWithReport(brname)
+	return err
+}
+
+// DeleteBridgeWithReport deletes a bridge along with its ports, interfaces,
+// controllers, mirrors, flow tables and sampling rows in one transaction, and
+// reports what was removed. Controllers shared with another bridge are kept.
+// A fake bridge is deleted like DeleteFakeBridge does. A nil report is
+// returned if the bridge doesn't exist
+func (client *ovsClient) DeleteBridgeWithReport(brname string) (*BridgeDeleteReport, error) {
 	bridgeUpdateLock.Lock()
 	defer bridgeUpdateLock.Unlock()
-	bridgeExists, err := client.BridgeExists(brname)
+	bridgeExists, err := client.bridgeRowExists(brname)
 	if err != nil {
-		return fmt.Errorf("Failed to retrieve the bridge info")
+		return nil, fmt.Errorf("Failed to retrieve the bridge info")
 	} else if !bridgeExists {
-		return nil
+		if parent, fakePort, err := client.getFakeBridge(brname); err == nil {
+			return client.deleteFakeBridgeLocked(parent, fakePort)
+		}
+		return nil, nil
 	}
-	bridgeUUID, err := client.getBridgeUUIDByName(brname)
+	bridge, err := client.getBridgeByName(brname)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	operations, report := client.newBridgeDeleteOperations(bridge)
+
 	delBridgeCondition := libovsdb.NewCondition("name", "==", brname)
 	deleteOp := libovsdb.Operation{
 		Op:    deleteOperation,
@@ -137,7 +335,7 @@ func (client *ovsClient) DeleteBridge(brname string) error {
 	}
 
 	// Deleting a Bridge row in Bridge table requires mutating the open_vswitch table
-	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: bridgeUUID}}
+	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: bridge.UUID}}
 	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
 	mutation := libovsdb.NewMutation("bridges", deleteOperation, mutateSet)
 	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: getRootUUID()})
@@ -150,35 +348,329 @@ func (client *ovsClient) DeleteBridge(brname string) error {
 		Where:     []interface{}{condition},
 	}
 
-	operations := []libovsdb.Operation{deleteOp, mutateOp}
-	return client.transact(operations, "delete bridge")
+	operations = append(operations, deleteOp, mutateOp)
+	if err = client.transact(operations, "delete bridge"); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// newBridgeDeleteOperations returns the operations deleting the rows owned
+// by the bridge. Most of them would be garbage collected by ovsdb-server, they
+// are deleted explicitly so that the report matches the transaction
+func (client *ovsClient) newBridgeDeleteOperations(bridge *OvsBridge) ([]libovsdb.Operation, *BridgeDeleteReport) {
+	report := &BridgeDeleteReport{
+		Bridge:                  bridge.Name,
+		Ports:                   make([]string, 0),
+		Interfaces:              make([]string, 0),
+		Controllers:             make([]string, 0),
+		Mirrors:                 make([]string, 0),
+		FlowTables:              make([]int, 0),
+		FlowSampleCollectorSets: make([]int, 0),
+	}
+	var operations []libovsdb.Operation
+	deleted := make(map[string]bool)
+	deleteRow := func(table, uuid string) {
+		if uuid == "" || deleted[uuid] {
+			return
+		}
+		deleted[uuid] = true
+		operations = append(operations, libovsdb.Operation{
+			Op:    deleteOperation,
+			Table: table,
+			Where: []interface{}{libovsdb.NewCondition("_uuid", "==", []string{"uuid", uuid})},
+		})
+	}
+
+	portCacheUpdateLock.RLock()
+	intfCacheUpdateLock.RLock()
+	for _, portUUID := range bridge.PortUUIDs {
+		port, ok := client.portCache[portUUID]
+		if !ok {
+			continue
+		}
+		for _, intfUUID := range port.IntfUUIDs {
+			if intf, ok := client.interfaceCache[intfUUID]; ok {
+				report.Interfaces = append(report.Interfaces, intf.Name)
+			}
+			deleteRow(interfaceTableName, intfUUID)
+		}
+		report.Ports = append(report.Ports, port.Name)
+		deleteRow(portTableName, portUUID)
+	}
+	intfCacheUpdateLock.RUnlock()
+	portCacheUpdateLock.RUnlock()
+
+	unreferenced := client.unreferencedControllers(bridge)
+	controllerCacheUpdateLock.RLock()
+	for _, uuid := range unreferenced {
+		if controller, ok := client.controllerCache[uuid]; ok {
+			report.Controllers = append(report.Controllers, controller.Target)
+		}
+		deleteRow(controllerTableName, uuid)
+	}
+	controllerCacheUpdateLock.RUnlock()
+
+	for tableID, uuid := range bridge.FlowTableUUIDs {
+		report.FlowTables = append(report.FlowTables, tableID)
+		deleteRow(flowTableName, uuid)
+	}
+	sort.Ints(report.FlowTables)
+
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	for _, uuid := range bridge.MirrorUUIDs {
+		if row, ok := cache[mirrorTableName][uuid]; ok {
+			name, _ := stringFromValue(row.Fields["name"])
+			report.Mirrors = append(report.Mirrors, name)
+		}
+		deleteRow(mirrorTableName, uuid)
+	}
+	report.NetFlow = bridge.NetFlowUUID != ""
+	deleteRow(netflowTableName, bridge.NetFlowUUID)
+	report.SFlow = bridge.SFlowUUID != ""
+	deleteRow(sflowTableName, bridge.SFlowUUID)
+	report.IPFIX = bridge.IPFIXUUID != ""
+	deleteRow(ipfixTableName, bridge.IPFIXUUID)
+	report.AutoAttach = bridge.AutoAttachUUID != ""
+	deleteRow(autoAttachTableName, bridge.AutoAttachUUID)
+
+	// The collector sets are root rows referring to the bridge, the bridge
+	// can't be deleted while they exist
+	for uuid, row := range cache[flowSampleCollectorTableName] {
+		bridges := uuidsFromValue(row.Fields["bridge"])
+		if len(bridges) != 1 || bridges[0] != bridge.UUID {
+			continue
+		}
+		id, _ := intFromValue(row.Fields["id"])
+		report.FlowSampleCollectorSets = append(report.FlowSampleCollectorSets, id)
+		deleteRow(flowSampleCollectorTableName, uuid)
+		deleteRow(ipfixTableName, optionalUUIDFromValue(row.Fields["ipfix"]))
+	}
+	sort.Ints(report.FlowSampleCollectorSets)
+	return operations, report
 }
 
-func (client *ovsClient) deleteAllPortsOnBridge(brname string) error {
-	bridgeExists, err := client.BridgeExists(brname)
+// UpdateBridge changes the settings of an existing bridge. The other_config
+// and external_ids keys of the spec are merged into the existing maps
+func (client *ovsClient) UpdateBridge(spec BridgeSpec) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridgeExists, err := client.bridgeRowExists(spec.Name)
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve the bridge info")
 	} else if !bridgeExists {
+		return fmt.Errorf("The bridge %s doesn't exist", spec.Name)
+	}
+	if err = client.checkDatapathSupported(spec.DatapathType); err != nil {
+		return err
+	}
+	if err = validateFailMode(spec.FailMode); err != nil {
+		return err
+	}
+	if err = validateProtocols(spec.Protocols); err != nil {
+		return err
+	}
+	if err = client.validateIdentity(spec); err != nil {
+		return err
+	}
+
+	condition := libovsdb.NewCondition("name", "==", spec.Name)
+	var operations []libovsdb.Operation
+
+	bridge := make(map[string]interface{})
+	if spec.DatapathType != "" {
+		bridge["datapath_type"] = spec.DatapathType
+	}
+	if spec.FailMode != "" {
+		bridge["fail_mode"] = spec.FailMode
+	}
+	if len(spec.Protocols) != 0 {
+		bridge["protocols"], _ = libovsdb.NewOvsSet(spec.Protocols)
+	}
+	if len(bridge) != 0 {
+		operations = append(operations, libovsdb.Operation{
+			Op:    updateOperation,
+			Table: bridgeTableName,
+			Row:   bridge,
+			Where: []interface{}{condition},
+		})
+	}
+
+	var mutations []interface{}
+	mutations = append(mutations, newMapUpdateMutations("other_config", spec.otherConfig())...)
+	mutations = append(mutations, newMapUpdateMutations("external_ids", spec.ExternalIDs)...)
+	if len(mutations) != 0 {
+		operations = append(operations, libovsdb.Operation{
+			Op:        mutateOperation,
+			Table:     bridgeTableName,
+			Mutations: mutations,
+			Where:     []interface{}{condition},
+		})
+	}
+
+	if spec.MTURequest > 0 {
+		intf := make(map[string]interface{})
+		intf["mtu_request"] = spec.MTURequest
+		operations = append(operations, libovsdb.Operation{
+			Op:    updateOperation,
+			Table: interfaceTableName,
+			Row:   intf,
+			Where: []interface{}{libovsdb.NewCondition("name", "==", spec.Name)},
+		})
+	}
+
+	if len(operations) == 0 {
 		return nil
 	}
+	return client.transact(operations, "update bridge")
+}
+
+// SetBridgeFailMode sets the fail mode of a bridge, an empty mode restores
+// the default behavior which is standalone
+func (client *ovsClient) SetBridgeFailMode(brname, failMode string) error {
+	if err := validateFailMode(failMode); err != nil {
+		return err
+	}
+	var value interface{} = failMode
+	if failMode == "" {
+		value, _ = libovsdb.NewOvsSet([]string{})
+	}
+	return client.updateBridgeColumn(brname, "fail_mode", value, "set bridge fail mode")
+}
 
-	portList, err := client.findAllPortUUIDsOnBridge(brname)
+// GetBridgeFailMode returns the fail mode of a bridge, empty if not set
+func (client *ovsClient) GetBridgeFailMode(brname string) (string, error) {
+	bridge, err := client.getBridgeByName(brname)
 	if err != nil {
+		return "", err
+	}
+	return bridge.FailMode, nil
+}
+
+// SetBridgeProtocols sets the OpenFlow versions enabled on a bridge, an
+// empty list restores the ovs default
+func (client *ovsClient) SetBridgeProtocols(brname string, protocols []string) error {
+	if err := validateProtocols(protocols); err != nil {
 		return err
 	}
-	if len(portList) != 0 {
-		for _, portUUID := range portList {
-			err = client.deletePortByUUID(brname, portUUID)
-			if err != nil {
-				return err
+	if protocols == nil {
+		protocols = []string{}
+	}
+	protocolSet, _ := libovsdb.NewOvsSet(protocols)
+	return client.updateBridgeColumn(brname, "protocols", protocolSet, "set bridge protocols")
+}
+
+// GetBridgeProtocols returns the OpenFlow versions enabled on a bridge
+func (client *ovsClient) GetBridgeProtocols(brname string) ([]string, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return nil, err
+	}
+	return bridge.Protocols, nil
+}
+
+// updateBridgeColumn sets a single column of an existing bridge
+func (client *ovsClient) updateBridgeColumn(brname, column string, value interface{}, action string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the bridge info")
+	} else if !bridgeExists {
+		return fmt.Errorf("The bridge %s doesn't exist", brname)
+	}
+	bridge := make(map[string]interface{})
+	bridge[column] = value
+	updateBrCondition := libovsdb.NewCondition("name", "==", brname)
+	updateOp := libovsdb.Operation{
+		Op:    updateOperation,
+		Table: bridgeTableName,
+		Row:   bridge,
+		Where: []interface{}{updateBrCondition},
+	}
+	return client.transact([]libovsdb.Operation{updateOp}, action)
+}
+
+// updateBridgeConfig updates the columns of an existing bridge and merges
+// the keys into its other_config within one transaction, the keys with an
+// empty value are removed
+func (client *ovsClient) updateBridgeConfig(brname string, bridge map[string]interface{}, config map[string]string, action string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	return client.updateBridgeConfigLocked(brname, bridge, config, action)
+}
+
+// updateBridgeConfigLocked works like updateBridgeConfig, the caller holds
+// bridgeUpdateLock
+func (client *ovsClient) updateBridgeConfigLocked(brname string, bridge map[string]interface{}, config map[string]string, action string) error {
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the bridge info")
+	} else if !bridgeExists {
+		return fmt.Errorf("The bridge %s doesn't exist", brname)
+	}
+	condition := libovsdb.NewCondition("name", "==", brname)
+	var operations []libovsdb.Operation
+	if len(bridge) != 0 {
+		operations = append(operations, libovsdb.Operation{
+			Op:    updateOperation,
+			Table: bridgeTableName,
+			Row:   bridge,
+			Where: []interface{}{condition},
+		})
+	}
+	if mutations := newMapUpdateMutations("other_config", config); len(mutations) != 0 {
+		operations = append(operations, libovsdb.Operation{
+			Op:        mutateOperation,
+			Table:     bridgeTableName,
+			Mutations: mutations,
+			Where:     []interface{}{condition},
+		})
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+	return client.transact(operations, action)
+}
+
+func validateFailMode(failMode string) error {
+	switch failMode {
+	case "", FailModeStandalone, FailModeSecure:
+		return nil
+	}
+	return fmt.Errorf("The fail mode %s is invalid, it should be %s or %s", failMode, FailModeStandalone, FailModeSecure)
+}
+
+func validateProtocols(protocols []string) error {
+	for _, protocol := range protocols {
+		valid := false
+		for _, allowed := range OpenFlowProtocols {
+			if protocol == allowed {
+				valid = true
+				break
 			}
 		}
+		if !valid {
+			return fmt.Errorf("The protocol %s is invalid, it should be one of %v", protocol, OpenFlowProtocols)
+		}
 	}
 	return nil
 }
 
-// BridgeExists is used to check if a bridge exists or not
+// BridgeExists is used to check if a bridge exists or not, fake bridges are
+// reported as well
 func (client *ovsClient) BridgeExists(brname string) (bool, error) {
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil || bridgeExists {
+		return bridgeExists, err
+	}
+	_, _, err = client.getFakeBridge(brname)
+	return err == nil, nil
+}
+
+// bridgeRowExists checks if a bridge row exists, fake bridges excluded
+func (client *ovsClient) bridgeRowExists(brname string) (bool, error) {
 	// if bridge name is invalid, return false
 	if brname == "" {
 		return false, fmt.Errorf("The bridge name is invalid")
@@ -193,58 +685,17 @@ func (client *ovsClient) BridgeExists(brname string) (bool, error) {
 	return false, nil
 }
 
+// UpdateBridgeController sets a single controller on the bridge
 func (client *ovsClient) UpdateBridgeController(brname, controller string) error {
 	bridgeUpdateLock.Lock()
 	defer bridgeUpdateLock.Unlock()
-	bridgeExists, err := client.BridgeExists(brname)
+	bridgeExists, err := client.bridgeRowExists(brname)
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve the bridge info")
 	} else if !bridgeExists {
 		return nil
 	}
-
-	bridgeUUID, err := client.getBridgeUUIDByName(brname)
-	if err != nil {
-		return err
-	}
-
-	namedControllerUUID := "gocontroller"
-	ctrler := make(map[string]interface{})
-	ctrler["target"] = controller
-
-	insertControllerOp := libovsdb.Operation{
-		Op:       insertOperation,
-		Table:    controllerTableName,
-		Row:      ctrler,
-		UUIDName: namedControllerUUID,
-	}
-	bridge := make(map[string]interface{})
-	bridge["controller"] = libovsdb.UUID{GoUUID: namedControllerUUID}
-
-	updateBrCondition := libovsdb.NewCondition("name", "==", brname)
-	updateOp := libovsdb.Operation{
-		Op:    updateOperation,
-		Table: bridgeTableName,
-		Row:   bridge,
-		Where: []interface{}{updateBrCondition},
-	}
-
-	// Update a Bridge row in Bridge table requires mutating the open_vswitch table
-	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: namedControllerUUID}}
-	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
-	mutation := libovsdb.NewMutation("controller", insertOperation, mutateSet)
-	condition := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: bridgeUUID})
-
-	// simple mutate operation
-	mutateOp := libovsdb.Operation{
-		Op:        mutateOperation,
-		Table:     bridgeTableName,
-		Mutations: []interface{}{mutation},
-		Where:     []interface{}{condition},
-	}
-
-	operations := []libovsdb.Operation{insertControllerOp, updateOp, mutateOp}
-	return client.transact(operations, "update bridge controller")
+	return client.setControllers(brname, []ControllerSpec{{Target: controller}})
 }
 
 func (client *ovsClient) getBridgeUUIDByName(brname string) (string, error) {
@@ -258,3 +709,19 @@ func (client *ovsClient) getBridgeUUIDByName(brname string) (string, error) {
 	}
 	return "", fmt.Errorf("The brdige name %s doesn't exist", brname)
 }
+
+// getBridgeByName returns a copy of the cached bridge
+func (client *ovsClient) getBridgeByName(brname string) (*OvsBridge, error) {
+	if brname == "" {
+		return nil, fmt.Errorf("The bridge name is invalid")
+	}
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	for _, bridge := range client.bridgeCache {
+		if bridge.Name == brname {
+			brObj := *bridge
+			return &brObj, nil
+		}
+	}
+	return nil, fmt.Errorf("The bridge %s doesn't exist", brname)
+}
@@ -16,8 +16,34 @@ func TestDeleteBridge(t *testing.T) {
 	// TODO
 }
 
-func TestDeleteAllPortsOnBridge(t *testing.T) {
-	// TODO
+func TestNewBridgeDeleteOperations(t *testing.T) {
+	testClient := &ovsClient{
+		bridgeCache: map[string]*OvsBridge{
+			"br1": {UUID: "br1", Name: "br1", PortUUIDs: []string{"p1"}, ControllerUUIDs: []string{"c1", "c2"},
+				NetFlowUUID: "nf1", FlowTableUUIDs: map[int]string{3: "ft3", 0: "ft0"}},
+			"br2": {UUID: "br2", Name: "br2", ControllerUUIDs: []string{"c2"}},
+		},
+		portCache:      map[string]*OvsPort{"p1": {UUID: "p1", Name: "br1", IntfUUIDs: []string{"i1"}}},
+		interfaceCache: map[string]*OvsInterface{"i1": {UUID: "i1", Name: "br1"}},
+		controllerCache: map[string]*OvsController{
+			"c1": {UUID: "c1", Target: "tcp:10.0.0.1:6653"},
+			"c2": {UUID: "c2", Target: "tcp:10.0.0.2:6653"},
+		},
+	}
+	operations, report := testClient.newBridgeDeleteOperations(testClient.bridgeCache["br1"])
+	// interface, port, controller, 2 flow tables and netflow
+	if len(operations) != 6 {
+		t.Fatalf("The %d delete operations are incorrect: %+v", len(operations), operations)
+	}
+	if len(report.Ports) != 1 || len(report.Interfaces) != 1 || !report.NetFlow {
+		t.Fatalf("The report %+v is incorrect", report)
+	}
+	if len(report.Controllers) != 1 || report.Controllers[0] != "tcp:10.0.0.1:6653" {
+		t.Fatalf("The shared controller should be kept, got %v", report.Controllers)
+	}
+	if len(report.FlowTables) != 2 || report.FlowTables[0] != 0 || report.FlowTables[1] != 3 {
+		t.Fatalf("The flow tables %v are incorrect", report.FlowTables)
+	}
 }
 
 func TestUpdateBridgeController(t *testing.T) {
@@ -27,3 +53,58 @@ func TestUpdateBridgeController(t *testing.T) {
 func TestGetBridgeUUIDByName(t *testing.T) {
 	// TODO
 }
+
+func TestBridgeSpecOtherConfig(t *testing.T) {
+	spec := BridgeSpec{
+		Name:          "br-test",
+		DatapathID:    "0000000000000001",
+		DisableInBand: true,
+		OtherConfig:   map[string]string{"datapath-id": "0000000000000002", "flow-eviction-threshold": "1000"},
+	}
+	config := spec.otherConfig()
+	if config["datapath-id"] != "0000000000000001" {
+		t.Fatalf("The typed datapath id should win, got %s", config["datapath-id"])
+	}
+	if config["disable-in-band"] != "true" || config["flow-eviction-threshold"] != "1000" {
+		t.Fatalf("The other config %+v is incorrect", config)
+	}
+	if _, ok := config["hwaddr"]; ok {
+		t.Fatal("The empty hwaddr should not be set")
+	}
+}
+
+func TestCreateBridgeWithSpec(t *testing.T) {
+	// TODO
+}
+
+func TestUpdateBridge(t *testing.T) {
+	// TODO
+}
+
+func TestValidateFailMode(t *testing.T) {
+	for _, failMode := range []string{"", FailModeStandalone, FailModeSecure} {
+		if err := validateFailMode(failMode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := validateFailMode("closed"); err == nil {
+		t.Fatal("An unknown fail mode should be rejected")
+	}
+}
+
+func TestValidateProtocols(t *testing.T) {
+	if err := validateProtocols([]string{"OpenFlow10", "OpenFlow13"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateProtocols([]string{"OpenFlow1.3"}); err == nil {
+		t.Fatal("An unknown protocol should be rejected")
+	}
+}
+
+func TestSetBridgeFailMode(t *testing.T) {
+	// TODO
+}
+
+func TestSetBridgeProtocols(t *testing.T) {
+	// TODO
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/rocksolidlabs/libovsdb"
 )
@@ -26,7 +27,7 @@ const (
 	portTableName       = "Port"
 	interfaceTableName  = "Interface"
 	controllerTableName = "Controller"
-	//flowTableName = ""
+	flowTableName       = "Flow_Table"
 )
 
 const (
@@ -46,24 +47,124 @@ type OvsObject interface {
 type OvsClient interface {
 	BridgeExists(brname string) (bool, error)
 	CreateBridge(brname string) error
+	CreateBridgeWithSpec(spec BridgeSpec) error
+	UpdateBridge(spec BridgeSpec) error
+	SetBridgeFailMode(brname, failMode string) error
+	GetBridgeFailMode(brname string) (string, error)
+	SetBridgeProtocols(brname string, protocols []string) error
+	GetBridgeProtocols(brname string) ([]string, error)
+	SetBridgeSTP(brname string, enable bool, spec STPSpec) error
+	SetBridgeRSTP(brname string, enable bool, spec RSTPSpec) error
+	GetBridgeSTPStatus(brname string) (BridgeSTPStatus, error)
+	GetBridgeRSTPStatus(brname string) (BridgeRSTPStatus, error)
+	SetPortSTP(portname string, spec PortSTPSpec) error
+	SetPortRSTP(portname string, spec PortSTPSpec) error
+	GetPortSTPStatus(portname string) (PortSTPStatus, error)
+	GetPortRSTPStatus(portname string) (PortRSTPStatus, error)
+	SetBridgeDatapathID(brname, dpid string) error
+	GetBridgeDatapathID(brname string) (string, error)
+	SetBridgeHWAddr(brname, hwaddr string) error
+	GetBridgeDatapathVersion(brname string) (string, error)
+	GetBridgeDatapath(brname string) (*OvsDatapath, error)
+	CheckDatapathIDs() error
+	CreateMirror(brname string, spec MirrorSpec) error
+	DeleteMirror(brname, mirrorname string) error
+	ListMirrors(brname string) ([]OvsMirror, error)
+	SetBridgeNetFlow(brname string, spec NetFlowSpec) error
+	ClearBridgeNetFlow(brname string) error
+	SetBridgeSFlow(brname string, spec SFlowSpec) error
+	ClearBridgeSFlow(brname string) error
+	SetBridgeIPFIX(brname string, spec IPFIXSpec) error
+	ClearBridgeIPFIX(brname string) error
+	GetBridgeFlowExport(brname string) (FlowExport, error)
+	AddFlowSampleCollectorSet(brname string, id int, spec IPFIXSpec) error
+	DeleteFlowSampleCollectorSet(brname string, id int) error
+	SetBridgeFlowTable(brname string, tableID int, spec FlowTableSpec) error
+	ClearBridgeFlowTable(brname string, tableID int) error
+	GetBridgeFlowTables(brname string) (map[int]OvsFlowTable, error)
+	SetBridgeFloodVLANs(brname string, vlans []int) error
+	GetBridgeFloodVLANs(brname string) ([]int, error)
+	SetBridgeMACLearning(brname string, spec MACLearningSpec) error
+	GetBridgeMACLearning(brname string) (MACLearningSpec, error)
+	SetBridgeAutoAttach(brname string, spec AutoAttachSpec) error
+	ClearBridgeAutoAttach(brname string) error
+	GetBridgeAutoAttach(brname string) (*OvsAutoAttach, error)
+	AddAutoAttachMapping(brname string, isid, vlan int) error
+	DeleteAutoAttachMapping(brname string, isid int) error
+	SetInterfaceLLDP(intfname string, enable bool) error
+	CreateTrunkPort(brname, portname string, trunks []int) error
+	UpdatePortVlan(portname string, spec VlanSpec) error
+	GetPortVlan(portname string) (VlanSpec, error)
+	CreateBond(brname, portname string, ifaces []string, spec BondSpec) error
+	AddBondMember(portname, iface string) error
+	RemoveBondMember(portname, iface string) error
+	GetBondStatus(portname string) (BondStatus, error)
+	CreateTunnelPort(brname, portname string, spec TunnelSpec) error
+	SyncOverlay(spec OverlaySpec) (*OverlayChanges, error)
+	SetBridgeMcastSnooping(brname string, enable bool, spec McastSnoopingSpec) error
+	GetBridgeMcastSnooping(brname string) (bool, McastSnoopingSpec, error)
+	SetPortMcastSnooping(portname string, spec PortMcastSnoopingSpec) error
+	GetPortMcastSnooping(portname string) (PortMcastSnoopingSpec, error)
+	CreateNetdevBridge(brname string) error
+	GetDatapathTypes() ([]string, error)
+	GetInterfaceTypes() ([]string, error)
+	DatapathTypeSupported(dptype string) (bool, error)
 	DeleteBridge(brname string) error
+	DeleteBridgeWithReport(brname string) (*BridgeDeleteReport, error)
+	CreateFakeBridge(brname, parent string, vlan int) error
+	DeleteFakeBridge(brname string) error
+	GetFakeBridge(brname string) (*OvsFakeBridge, error)
 	UpdateBridgeController(brname, controller string) error
+	SetControllers(brname string, specs []ControllerSpec) error
+	DelController(brname string) error
+	GetControllers(brname string) ([]OvsController, error)
+	WaitForControllerConnected(brname string, timeout time.Duration) error
 	CreateInternalPort(brname, portname string, vlantag int) error
 	CreateVethPort(brname, portname string, vlantag int) error
 	CreatePatchPort(brname, portname, peername string) error
+	CreateDpdkPort(brname, portname, devargs string, vlantag int) error
+	CreateVhostUserPort(brname, portname string, vlantag int) error
+	CreateVhostUserClientPort(brname, portname, socketPath string, vlantag int) error
 	DeletePort(brname, porname string) error
+	CreatePorts(brname string, specs []PortSpec) error
+	DeletePorts(brname string, portnames []string) error
 	UpdatePortTagByName(brname, portname string, vlantag int) error
+	UntagPortByName(brname, portname string) error
 	FindAllPortsOnBridge(brname string) ([]string, error)
 	PortExistsOnBridge(portname, brname string) (bool, error)
 	RemoveInterfaceFromPort(portname, interfaceUUID string) error
+	Lock(lockID string) (bool, error)
+	Steal(lockID string) error
+	Unlock(lockID string) error
+	HasLock(lockID string) bool
+	AssertLock(lockID string)
+	RegisterLockHandler(handler LockHandler)
 	Disconnect()
 }
 
 type ovsClient struct {
-	dbClient       *libovsdb.OvsdbClient
-	bridgeCache    map[string]*OvsBridge
-	portCache      map[string]*OvsPort
-	interfaceCache map[string]*OvsInterface
+	dbClient        *libovsdb.OvsdbClient
+	schema          *ovsSchema
+	network         string
+	address         string
+	bridgeCache     map[string]*OvsBridge
+	portCache       map[string]*OvsPort
+	interfaceCache  map[string]*OvsInterface
+	controllerCache map[string]*OvsController
+	flowTableCache  map[string]*OvsFlowTable
+	autoAttachCache map[string]*OvsAutoAttach
+	lockSession     *lockSession
+	lockHandlers    []LockHandler
+	heldLocks       map[string]bool
+	assertLockID    string
+	// lockSessionLost tells if the next lock session is a reconnection
+	lockSessionLost bool
+	// lockEvents queues the lock changes for the LockHandlers, which are
+	// notified by a goroutine running while lockNotifying is set
+	lockEvents    []lockEvent
+	lockNotifying bool
+	// dbSessionLost tells if the main session has to be reopened
+	dbSessionLost bool
 }
 
 var client *ovsClient
@@ -77,48 +178,55 @@ var intfUpdateLock sync.RWMutex
 var bridgeCacheUpdateLock sync.RWMutex
 var portCacheUpdateLock sync.RWMutex
 var intfCacheUpdateLock sync.RWMutex
+var controllerCacheUpdateLock sync.RWMutex
+var flowTableCacheUpdateLock sync.RWMutex
+var autoAttachCacheUpdateLock sync.RWMutex
 var populateCacheLock sync.RWMutex
+var dbSessionLock sync.Mutex
 
 // GetOVSClient is used for
 func GetOVSClient(contype, endpoint string) (OvsClient, error) {
 	if client != nil {
 		return client, nil
 	}
-	var dbclient *libovsdb.OvsdbClient
-	var err error
+	var address string
 	switch contype {
 	case "tcp":
-		if endpoint == "" {
-			dbclient, err = libovsdb.Connect(defaultTCPHost, defaultTCPPort)
-		} else {
-			var host, port string
-			host, port, err = net.SplitHostPort(endpoint)
-			if err != nil {
-				return nil, err
-			}
-			var portInt int
-			if portInt, err = strconv.Atoi(port); err != nil {
-				return nil, err
-			}
-			dbclient, err = libovsdb.Connect(host, portInt)
+		address = endpoint
+		if address == "" {
+			address = net.JoinHostPort(defaultTCPHost, strconv.Itoa(defaultTCPPort))
 		}
 	case "unix":
-		if endpoint == "" {
-			endpoint = defaultUnixEndpoint
+		address = endpoint
+		if address == "" {
+			address = defaultUnixEndpoint
 		}
-		dbclient, err = libovsdb.ConnectWithUnixSocket(endpoint)
 	default:
 		return nil, fmt.Errorf("GetOVSClient: Unsupported connection type %q.", contype)
 	}
+	dbclient, err := dialOvsdb(contype, address)
+	if err != nil {
+		return nil, err
+	}
+	var dbSchema *libovsdb.DatabaseSchema
+	span := startSpan("get_schema", "connect")
+	dbSchema, err = dbclient.GetSchema(defaultOvsDB)
+	span.End(err)
 	if err != nil {
+		return nil, fmt.Errorf("GetOVSClient: Failed to fetch the %s schema due to %s", defaultOvsDB, err.Error())
+	}
+	var schema *ovsSchema
+	if schema, err = newOvsSchema(dbSchema); err != nil {
 		return nil, err
 	}
+
 	var notfr notifier
 	dbclient.Register(notfr)
 
 	cache = make(map[string]map[string]libovsdb.Row)
 
-	client = &ovsClient{dbClient: dbclient}
+	client = &ovsClient{dbClient: dbclient, schema: schema, network: contype, address: address}
+	client.heldLocks = make(map[string]bool)
 	if client.bridgeCache == nil {
 		client.bridgeCache = make(map[string]*OvsBridge)
 	}
@@ -128,41 +236,172 @@ func GetOVSClient(contype, endpoint string) (OvsClient, error) {
 	if client.interfaceCache == nil {
 		client.interfaceCache = make(map[string]*OvsInterface)
 	}
+	if client.controllerCache == nil {
+		client.controllerCache = make(map[string]*OvsController)
+	}
+	if client.flowTableCache == nil {
+		client.flowTableCache = make(map[string]*OvsFlowTable)
+	}
+	if client.autoAttachCache == nil {
+		client.autoAttachCache = make(map[string]*OvsAutoAttach)
+	}
+
+	if err = monitorOvsdb(dbclient, "connect"); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
 
-	var initial *libovsdb.TableUpdates
-	initial, err = dbclient.MonitorAll(defaultOvsDB, "")
+// dialOvsdb opens a session towards ovsdb-server
+func dialOvsdb(network, address string) (*libovsdb.OvsdbClient, error) {
+	if network == "unix" {
+		return libovsdb.ConnectWithUnixSocket(address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	portInt, err := strconv.Atoi(port)
 	if err != nil {
 		return nil, err
 	}
+	return libovsdb.Connect(host, portInt)
+}
+
+// monitorOvsdb monitors the whole database and fills the cache with the
+// initial content
+func monitorOvsdb(dbclient *libovsdb.OvsdbClient, action string) error {
+	span := startSpan("monitor", action)
+	initial, err := dbclient.MonitorAll(defaultOvsDB, "")
+	span.End(err)
+	if err != nil {
+		return err
+	}
 	populateCache(*initial)
-	return client, nil
+	return nil
+}
+
+// getDBClient returns the main session, reopening it if it was lost. The
+// caches are rebuilt since the updates sent meanwhile are missed
+func (client *ovsClient) getDBClient() (*libovsdb.OvsdbClient, error) {
+	dbSessionLock.Lock()
+	defer dbSessionLock.Unlock()
+	if !client.dbSessionLost {
+		return client.dbClient, nil
+	}
+	dbclient, err := dialOvsdb(client.network, client.address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to reconnect to ovsdb due to %s", err.Error())
+	}
+	var notfr notifier
+	dbclient.Register(notfr)
+	client.resetCaches()
+	if err = monitorOvsdb(dbclient, "reconnect"); err != nil {
+		dbclient.Disconnect()
+		return nil, fmt.Errorf("Failed to reconnect to ovsdb due to %s", err.Error())
+	}
+	client.dbClient = dbclient
+	client.dbSessionLost = false
+	getInstrumentation().Reconnected(ovsdbSession)
+	return dbclient, nil
+}
+
+// dbSessionClosed marks the main session as lost, the next transaction
+// reopens it
+func (client *ovsClient) dbSessionClosed(dbclient *libovsdb.OvsdbClient) {
+	dbSessionLock.Lock()
+	defer dbSessionLock.Unlock()
+	if dbclient != client.dbClient || client.dbSessionLost {
+		return
+	}
+	client.dbSessionLost = true
+	getInstrumentation().Disconnected(ovsdbSession)
+}
+
+// resetCaches drops every cached row
+func (client *ovsClient) resetCaches() {
+	populateCacheLock.Lock()
+	defer populateCacheLock.Unlock()
+	for table, rows := range cache {
+		for uuid := range rows {
+			client.removeOvsObjCacheByRow(table, uuid)
+		}
+		getInstrumentation().CacheSizeChanged(table, 0)
+	}
+	cache = make(map[string]map[string]libovsdb.Row)
 }
 
 func (client *ovsClient) Disconnect() {
+	client.closeLockSession()
 	client.dbClient.Disconnect()
 }
 
 func (client *ovsClient) transact(operations []libovsdb.Operation, action string) error {
-	reply, _ := client.dbClient.Transact(defaultOvsDB, operations...)
+	_, err := client.transactWithIndex(operations, action)
+	return err
+}
+
+// transactWithIndex works like transact but also returns the index of the
+// operation which caused the failure, or -1 if no single operation is to blame
+func (client *ovsClient) transactWithIndex(operations []libovsdb.Operation, action string) (int, error) {
+	_, index, err := client.transactWithReply(operations, action)
+	return index, err
+}
+
+// transactWithReply works like transactWithIndex but also returns the replies
+// of the operations, e.g. the rows of a select
+func (client *ovsClient) transactWithReply(operations []libovsdb.Operation, action string) (reply []libovsdb.OperationResult, index int, err error) {
+	start := time.Now()
+	defer func() {
+		getInstrumentation().TransactionDone(action, time.Since(start), err)
+	}()
+
+	if client.schema != nil {
+		for i, operation := range operations {
+			if err := client.schema.validateOperation(operation); err != nil {
+				return nil, i, fmt.Errorf("%s failed due to invalid operation: %s", action, err.Error())
+			}
+		}
+	}
+
+	lockUpdateLock.Lock()
+	lockID := client.assertLockID
+	lockUpdateLock.Unlock()
+	if lockID != "" {
+		if reply, err = client.transactAssertingLock(lockID, operations); err != nil {
+			return nil, -1, fmt.Errorf("%s failed due to %s", action, err.Error())
+		}
+	} else {
+		var dbclient *libovsdb.OvsdbClient
+		if dbclient, err = client.getDBClient(); err != nil {
+			return nil, -1, fmt.Errorf("%s failed due to %s", action, err.Error())
+		}
+		span := startSpan("transact", action)
+		reply, err = dbclient.Transact(defaultOvsDB, operations...)
+		span.End(err)
+		if err != nil {
+			return nil, -1, fmt.Errorf("%s failed due to %s", action, err.Error())
+		}
+	}
 
 	if len(reply) < len(operations) {
-		return fmt.Errorf("%s failed due to Number of Replies should be at least equal to number of Operations", action)
+		return nil, -1, fmt.Errorf("%s failed due to Number of Replies should be at least equal to number of Operations", action)
 	}
 	//ok := true
 	for i, o := range reply {
 		if o.Error != "" {
 			//ok = false
 			if i < len(operations) {
-				return fmt.Errorf("%s transaction Failed due to an error : %s details: %s in %+v", action, o.Error, o.Details, operations[i])
+				return nil, i, fmt.Errorf("%s transaction Failed due to an error : %s details: %s in %+v", action, o.Error, o.Details, operations[i])
 			}
-			return fmt.Errorf("%s transaction Failed due to an error :%s", action, o.Error)
+			return nil, -1, fmt.Errorf("%s transaction Failed due to an error :%s", action, o.Error)
 		}
 	}
 	//if ok {
 	//	log.Println(action, "successful: ", reply[0].UUID.GoUUID)
 	//}
 
-	return nil
+	return reply, -1, nil
 }
 
 type notifier struct {
@@ -171,6 +410,9 @@ type notifier struct {
 func (n notifier) Update(context interface{}, tableUpdates libovsdb.TableUpdates) {
 	populateCache(tableUpdates)
 }
+
+// Locks are never taken on the libovsdb session, they are handled by the
+// lock session instead
 func (n notifier) Locked([]interface{}) {
 }
 func (n notifier) Stolen([]interface{}) {
@@ -179,7 +421,10 @@ func (n notifier) Echo([]interface{}) {
 }
 func (n notifier) Disconnect([]interface{}) {
 }
-func (n notifier) Disconnected(*libovsdb.OvsdbClient) {
+func (n notifier) Disconnected(dbclient *libovsdb.OvsdbClient) {
+	if client != nil {
+		client.dbSessionClosed(dbclient)
+	}
 }
 
 func (client *ovsClient) updateOvsObjCacheByRow(objtype, uuid string, row *libovsdb.Row) (err error) {
@@ -214,6 +459,30 @@ func (client *ovsClient) updateOvsObjCacheByRow(objtype, uuid string, row *libov
 		intfCacheUpdateLock.Unlock()
 		//data, _ := json.MarshalIndent(intfObj, "", "    ")
 		//fmt.Println(string(data))
+	case controllerTableName:
+		controllerObj := &OvsController{UUID: uuid}
+		if err = controllerObj.ReadFromDBRow(row); err != nil {
+			return
+		}
+		controllerCacheUpdateLock.Lock()
+		client.controllerCache[uuid] = controllerObj
+		controllerCacheUpdateLock.Unlock()
+	case flowTableName:
+		flowTableObj := &OvsFlowTable{UUID: uuid}
+		if err = flowTableObj.ReadFromDBRow(row); err != nil {
+			return
+		}
+		flowTableCacheUpdateLock.Lock()
+		client.flowTableCache[uuid] = flowTableObj
+		flowTableCacheUpdateLock.Unlock()
+	case autoAttachTableName:
+		autoAttachObj := &OvsAutoAttach{UUID: uuid}
+		if err = autoAttachObj.ReadFromDBRow(row); err != nil {
+			return
+		}
+		autoAttachCacheUpdateLock.Lock()
+		client.autoAttachCache[uuid] = autoAttachObj
+		autoAttachCacheUpdateLock.Unlock()
 	}
 	return
 }
@@ -238,6 +507,24 @@ func (client *ovsClient) removeOvsObjCacheByRow(objtype, uuid string) error {
 			delete(client.interfaceCache, uuid)
 			intfCacheUpdateLock.Unlock()
 		}
+	case controllerTableName:
+		if _, ok := client.controllerCache[uuid]; ok {
+			controllerCacheUpdateLock.Lock()
+			delete(client.controllerCache, uuid)
+			controllerCacheUpdateLock.Unlock()
+		}
+	case flowTableName:
+		if _, ok := client.flowTableCache[uuid]; ok {
+			flowTableCacheUpdateLock.Lock()
+			delete(client.flowTableCache, uuid)
+			flowTableCacheUpdateLock.Unlock()
+		}
+	case autoAttachTableName:
+		if _, ok := client.autoAttachCache[uuid]; ok {
+			autoAttachCacheUpdateLock.Lock()
+			delete(client.autoAttachCache, uuid)
+			autoAttachCacheUpdateLock.Unlock()
+		}
 	}
 	return nil
 }
@@ -250,6 +537,7 @@ func populateCache(updates libovsdb.TableUpdates) (err error) {
 			cache[table] = make(map[string]libovsdb.Row)
 
 		}
+		getInstrumentation().MonitorRowsUpdated(table, len(tableUpdate.Rows))
 		for uuid, row := range tableUpdate.Rows {
 			empty := libovsdb.Row{}
 			if !reflect.DeepEqual(row.New, empty) {
@@ -266,13 +554,173 @@ func populateCache(updates libovsdb.TableUpdates) (err error) {
 				}
 			}
 		}
+		getInstrumentation().CacheSizeChanged(table, len(cache[table]))
 	}
 	return
 }
 
+// newMapUpdateMutations returns the mutations setting the given keys of a
+// map column, an empty value removes the key. The keys are deleted first
+// since inserting an existing key doesn't change its value
+func newMapUpdateMutations(column string, values map[string]string) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(values))
+	inserted := make(map[string]string)
+	for key, value := range values {
+		keys = append(keys, key)
+		if value != "" {
+			inserted[key] = value
+		}
+	}
+	keySet, _ := libovsdb.NewOvsSet(keys)
+	mutations := []interface{}{libovsdb.NewMutation(column, deleteOperation, keySet)}
+	if len(inserted) != 0 {
+		valueMap, _ := libovsdb.NewOvsMap(inserted)
+		mutations = append(mutations, libovsdb.NewMutation(column, insertOperation, valueMap))
+	}
+	return mutations
+}
+
+// intSetting is an integer config key with its valid range, a nil value
+// restores the ovs default
+type intSetting struct {
+	key   string
+	value *int
+	min   int64
+	max   int64
+}
+
+// newIntConfig validates the settings and returns the other_config keys to
+// set. Nil values are returned empty, so that newMapUpdateMutations removes
+// their keys
+func newIntConfig(settings []intSetting) (map[string]string, error) {
+	config := make(map[string]string)
+	for _, setting := range settings {
+		if setting.value == nil {
+			config[setting.key] = ""
+			continue
+		}
+		value := *setting.value
+		if int64(value) < setting.min || int64(value) > setting.max {
+			return nil, fmt.Errorf("The %s value %d is not in range %d to %d", setting.key, value, setting.min, setting.max)
+		}
+		config[setting.key] = strconv.Itoa(value)
+	}
+	return config, nil
+}
+
+// setConfigKeys returns the keys of the config with a value, for the rows
+// being inserted
+func setConfigKeys(config map[string]string) map[string]string {
+	set := make(map[string]string)
+	for key, value := range config {
+		if value != "" {
+			set[key] = value
+		}
+	}
+	return set
+}
+
+// nonZeroInt returns nil for the settings where zero means the ovs default
+func nonZeroInt(value int) *int {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
 func getRootUUID() string {
 	for uuid := range cache[defaultOvsDB] {
 		return uuid
 	}
 	return ""
 }
+
+// stringsFromValue reads a column holding either a single string or a set
+func stringsFromValue(value interface{}) []string {
+	values := make([]string, 0)
+	switch value.(type) {
+	case string:
+		values = append(values, value.(string))
+	case libovsdb.OvsSet:
+		for _, elem := range value.(libovsdb.OvsSet).GoSet {
+			if str, ok := elem.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+	return values
+}
+
+// intsFromValue reads a column holding either a single integer or a set
+func intsFromValue(value interface{}) []int {
+	values := make([]int, 0)
+	switch value.(type) {
+	case float64:
+		values = append(values, int(value.(float64)))
+	case libovsdb.OvsSet:
+		for _, elem := range value.(libovsdb.OvsSet).GoSet {
+			if number, ok := elem.(float64); ok {
+				values = append(values, int(number))
+			}
+		}
+	}
+	return values
+}
+
+// uuidsFromValue reads a column holding either a single uuid or a set
+func uuidsFromValue(value interface{}) []string {
+	uuids := make([]string, 0)
+	switch value.(type) {
+	case libovsdb.UUID:
+		uuids = append(uuids, value.(libovsdb.UUID).GoUUID)
+	case libovsdb.OvsSet:
+		for _, elem := range value.(libovsdb.OvsSet).GoSet {
+			if uuid, ok := elem.(libovsdb.UUID); ok {
+				uuids = append(uuids, uuid.GoUUID)
+			}
+		}
+	}
+	return uuids
+}
+
+// optionalUUIDFromValue reads an optional reference column, empty if unset
+func optionalUUIDFromValue(value interface{}) string {
+	if uuids := uuidsFromValue(value); len(uuids) == 1 {
+		return uuids[0]
+	}
+	return ""
+}
+
+// mapFromValue reads a column holding a map of strings
+func mapFromValue(value interface{}) map[string]string {
+	values := make(map[string]string)
+	if ovsMap, ok := value.(libovsdb.OvsMap); ok {
+		for key, elem := range ovsMap.GoMap {
+			values[fmt.Sprintf("%v", key)] = fmt.Sprintf("%v", elem)
+		}
+	}
+	return values
+}
+
+// intFromValue reads an optional integer column, ok is false if it is empty
+func intFromValue(value interface{}) (int, bool) {
+	if number, ok := value.(float64); ok {
+		return int(number), true
+	}
+	return 0, false
+}
+
+// boolFromValue reads an optional boolean column, ok is false if it is empty
+func boolFromValue(value interface{}) (bool, bool) {
+	flag, ok := value.(bool)
+	return flag, ok
+}
+
+// stringFromValue reads an optional string column, ok is false if it is empty
+func stringFromValue(value interface{}) (string, bool) {
+	str, ok := value.(string)
+	return str, ok
+}
@@ -5,7 +5,7 @@ import (
 	"os"
 	"testing"
 
-	"github.com/socketplane/libovsdb"
+	"github.com/rocksolidlabs/libovsdb"
 )
 
 func preparingEnv() *ovsClient {
@@ -96,3 +96,75 @@ func TestGetRootUUID(t *testing.T) {
 	}
 	cache = tmpCache
 }
+
+func TestNewMapUpdateMutations(t *testing.T) {
+	if mutations := newMapUpdateMutations("other_config", nil); mutations != nil {
+		t.Fatalf("No mutation is expected for an empty map, got %+v", mutations)
+	}
+	mutations := newMapUpdateMutations("other_config", map[string]string{"hwaddr": "00:00:00:00:00:01"})
+	if len(mutations) != 2 {
+		t.Fatalf("Two mutations are expected, got %+v", mutations)
+	}
+	if mutator := mutations[0].([]interface{})[1]; mutator != deleteOperation {
+		t.Fatalf("The keys should be deleted first, got %v", mutator)
+	}
+	if mutations = newMapUpdateMutations("other_config", map[string]string{"hwaddr": ""}); len(mutations) != 1 {
+		t.Fatalf("An empty value should only remove the key, got %+v", mutations)
+	}
+}
+
+func TestNewIntConfig(t *testing.T) {
+	priority := 0
+	config, err := newIntConfig([]intSetting{
+		{"stp-priority", &priority, 0, 65535},
+		{"stp-hello-time", nonZeroInt(0), 1, 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config["stp-priority"] != "0" {
+		t.Fatalf("The stp priority %s is incorrect", config["stp-priority"])
+	}
+	if value, ok := config["stp-hello-time"]; !ok || value != "" {
+		t.Fatal("The zero hello time should be removed to restore the default")
+	}
+	if _, err = newIntConfig([]intSetting{{"stp-max-age", nonZeroInt(50), 6, 40}}); err == nil {
+		t.Fatal("An out of range max age should be rejected")
+	}
+}
+
+type sessionRecorder struct {
+	nopInstrumentation
+	disconnects int
+}
+
+func (recorder *sessionRecorder) Disconnected(string) {
+	recorder.disconnects++
+}
+
+func TestDBSessionClosed(t *testing.T) {
+	recorder := &sessionRecorder{}
+	SetInstrumentation(recorder)
+	defer SetInstrumentation(nil)
+	testClient := &ovsClient{dbClient: &libovsdb.OvsdbClient{}}
+	testClient.dbSessionClosed(&libovsdb.OvsdbClient{})
+	if testClient.dbSessionLost {
+		t.Fatal("The loss of a former session should be ignored")
+	}
+	testClient.dbSessionClosed(testClient.dbClient)
+	testClient.dbSessionClosed(testClient.dbClient)
+	if !testClient.dbSessionLost || recorder.disconnects != 1 {
+		t.Fatalf("The session should be lost once, got %d disconnects", recorder.disconnects)
+	}
+}
+
+func TestResetCaches(t *testing.T) {
+	tmpCache := cache
+	defer func() { cache = tmpCache }()
+	cache = map[string]map[string]libovsdb.Row{portTableName: {"p1": libovsdb.Row{}}}
+	testClient := &ovsClient{portCache: map[string]*OvsPort{"p1": {UUID: "p1", Name: "p1"}}}
+	testClient.resetCaches()
+	if len(cache) != 0 || len(testClient.portCache) != 0 {
+		t.Fatalf("The caches should be empty, got %v and %v", cache, testClient.portCache)
+	}
+}
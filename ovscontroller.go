@@ -0,0 +1,243 @@
+package goovs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const controllerPollInterval = 100 * time.Millisecond
+
+// OvsController is the structure represents a controller row
+type OvsController struct {
+	UUID                string            `json:"_uuid"`
+	Target              string            `json:"target"`
+	ConnectionMode      string            `json:"connection_mode"`
+	InactivityProbe     int               `json:"inactivity_probe"`
+	MaxBackoff          int               `json:"max_backoff"`
+	EnableAsyncMessages *bool             `json:"enable_async_messages"`
+	OtherConfig         map[string]string `json:"other_config"`
+	IsConnected         bool              `json:"is_connected"`
+	Role                string            `json:"role"`
+	Status              ControllerStatus  `json:"status"`
+}
+
+// ControllerStatus is the connection status reported by ovs-vswitchd
+type ControllerStatus struct {
+	State              string `json:"state"`
+	SecSinceConnect    int    `json:"sec_since_connect"`
+	SecSinceDisconnect int    `json:"sec_since_disconnect"`
+	LastError          string `json:"last_error"`
+}
+
+// ControllerSpec describes a controller of a bridge. Zero values leave the
+// settings to the ovs defaults
+type ControllerSpec struct {
+	Target              string
+	ConnectionMode      string
+	InactivityProbe     int
+	MaxBackoff          int
+	EnableAsyncMessages *bool
+	OtherConfig         map[string]string
+}
+
+// ReadFromDBRow is used to initialize the object from a row
+func (controller *OvsController) ReadFromDBRow(row *libovsdb.Row) error {
+	if controller.OtherConfig == nil {
+		controller.OtherConfig = make(map[string]string)
+	}
+	for field, value := range row.Fields {
+		switch field {
+		case "target":
+			controller.Target = value.(string)
+		case "connection_mode":
+			controller.ConnectionMode, _ = stringFromValue(value)
+		case "inactivity_probe":
+			controller.InactivityProbe, _ = intFromValue(value)
+		case "max_backoff":
+			controller.MaxBackoff, _ = intFromValue(value)
+		case "enable_async_messages":
+			if enabled, ok := boolFromValue(value); ok {
+				controller.EnableAsyncMessages = &enabled
+			}
+		case "other_config":
+			controller.OtherConfig = mapFromValue(value)
+		case "is_connected":
+			controller.IsConnected = value.(bool)
+		case "role":
+			controller.Role, _ = stringFromValue(value)
+		case "status":
+			controller.Status.readFromMap(mapFromValue(value))
+		}
+	}
+	return nil
+}
+
+func (status *ControllerStatus) readFromMap(values map[string]string) {
+	status.State = values["state"]
+	status.SecSinceConnect, _ = strconv.Atoi(values["sec_since_connect"])
+	status.SecSinceDisconnect, _ = strconv.Atoi(values["sec_since_disconnect"])
+	status.LastError = values["last_error"]
+}
+
+// newControllerRow builds the controller row to insert for a spec
+func newControllerRow(spec ControllerSpec) (map[string]interface{}, error) {
+	if spec.Target == "" {
+		return nil, fmt.Errorf("The controller target is invalid")
+	}
+	ctrler := make(map[string]interface{})
+	ctrler["target"] = spec.Target
+	switch spec.ConnectionMode {
+	case "":
+	case "in-band", "out-of-band":
+		ctrler["connection_mode"] = spec.ConnectionMode
+	default:
+		return nil, fmt.Errorf("The controller connection mode %s is invalid", spec.ConnectionMode)
+	}
+	if spec.InactivityProbe < 0 {
+		return nil, fmt.Errorf("The controller inactivity probe %d is invalid", spec.InactivityProbe)
+	} else if spec.InactivityProbe > 0 {
+		ctrler["inactivity_probe"] = spec.InactivityProbe
+	}
+	if spec.MaxBackoff < 0 {
+		return nil, fmt.Errorf("The controller max backoff %d is invalid", spec.MaxBackoff)
+	} else if spec.MaxBackoff > 0 {
+		ctrler["max_backoff"] = spec.MaxBackoff
+	}
+	if spec.EnableAsyncMessages != nil {
+		ctrler["enable_async_messages"] = *spec.EnableAsyncMessages
+	}
+	if len(spec.OtherConfig) != 0 {
+		ctrler["other_config"], _ = libovsdb.NewOvsMap(spec.OtherConfig)
+	}
+	return ctrler, nil
+}
+
+// SetControllers replaces the controllers of a bridge, the controller rows
+// which are no longer referenced are deleted in the same transaction
+func (client *ovsClient) SetControllers(brname string, specs []ControllerSpec) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	return client.setControllers(brname, specs)
+}
+
+// DelController removes all the controllers of a bridge
+func (client *ovsClient) DelController(brname string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	return client.setControllers(brname, nil)
+}
+
+// GetControllers returns the controllers of a bridge
+func (client *ovsClient) GetControllers(brname string) ([]OvsController, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return nil, err
+	}
+	controllerCacheUpdateLock.RLock()
+	defer controllerCacheUpdateLock.RUnlock()
+	controllers := make([]OvsController, 0, len(bridge.ControllerUUIDs))
+	for _, uuid := range bridge.ControllerUUIDs {
+		if controller, ok := client.controllerCache[uuid]; ok {
+			controllers = append(controllers, *controller)
+		}
+	}
+	return controllers, nil
+}
+
+// WaitForControllerConnected waits until at least one controller of the
+// bridge is connected, or returns an error once the timeout expires
+func (client *ovsClient) WaitForControllerConnected(brname string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		controllers, err := client.GetControllers(brname)
+		if err != nil {
+			return err
+		}
+		for _, controller := range controllers {
+			if controller.IsConnected {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			if len(controllers) == 0 {
+				return fmt.Errorf("The bridge %s has no controller", brname)
+			}
+			return fmt.Errorf("No controller of bridge %s is connected after %s, the last error is %q", brname, timeout, controllers[0].Status.LastError)
+		}
+		time.Sleep(controllerPollInterval)
+	}
+}
+
+func (client *ovsClient) setControllers(brname string, specs []ControllerSpec) error {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return err
+	}
+
+	var operations []libovsdb.Operation
+	namedControllerUUIDs := make([]libovsdb.UUID, 0, len(specs))
+	for index, spec := range specs {
+		ctrler, err := newControllerRow(spec)
+		if err != nil {
+			return err
+		}
+		namedControllerUUID := fmt.Sprintf("gocontroller%d", index)
+		operations = append(operations, libovsdb.Operation{
+			Op:       insertOperation,
+			Table:    controllerTableName,
+			Row:      ctrler,
+			UUIDName: namedControllerUUID,
+		})
+		namedControllerUUIDs = append(namedControllerUUIDs, libovsdb.UUID{GoUUID: namedControllerUUID})
+	}
+
+	// The controller set of the bridge is replaced as a whole
+	controllerSet, _ := libovsdb.NewOvsSet(namedControllerUUIDs)
+	row := make(map[string]interface{})
+	row["controller"] = controllerSet
+	updateBrCondition := libovsdb.NewCondition("name", "==", brname)
+	operations = append(operations, libovsdb.Operation{
+		Op:    updateOperation,
+		Table: bridgeTableName,
+		Row:   row,
+		Where: []interface{}{updateBrCondition},
+	})
+
+	for _, uuid := range client.unreferencedControllers(bridge) {
+		deleteCondition := libovsdb.NewCondition("_uuid", "==", []string{"uuid", uuid})
+		operations = append(operations, libovsdb.Operation{
+			Op:    deleteOperation,
+			Table: controllerTableName,
+			Where: []interface{}{deleteCondition},
+		})
+	}
+	return client.transact(operations, "set bridge controllers")
+}
+
+// unreferencedControllers returns the controllers of the bridge which no
+// other bridge refers to
+func (client *ovsClient) unreferencedControllers(bridge *OvsBridge) []string {
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	var uuids []string
+	for _, uuid := range bridge.ControllerUUIDs {
+		referenced := false
+		for brUUID, other := range client.bridgeCache {
+			if brUUID == bridge.UUID {
+				continue
+			}
+			for _, otherUUID := range other.ControllerUUIDs {
+				if otherUUID == uuid {
+					referenced = true
+				}
+			}
+		}
+		if !referenced {
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
@@ -0,0 +1,102 @@
+package goovs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestControllerReadFromDBRow(t *testing.T) {
+	controller := &OvsController{UUID: "abcde12345"}
+	row := &libovsdb.Row{Fields: map[string]interface{}{
+		"target":                "tcp:127.0.0.1:6653",
+		"connection_mode":       libovsdb.OvsSet{GoSet: []interface{}{}},
+		"inactivity_probe":      float64(5000),
+		"enable_async_messages": false,
+	}}
+	if err := controller.ReadFromDBRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if controller.Target != "tcp:127.0.0.1:6653" || controller.ConnectionMode != "" || controller.InactivityProbe != 5000 {
+		t.Fatalf("The controller %+v is incorrect", controller)
+	}
+	if controller.EnableAsyncMessages == nil || *controller.EnableAsyncMessages {
+		t.Fatal("The async messages should be disabled")
+	}
+}
+
+func TestNewControllerRow(t *testing.T) {
+	if _, err := newControllerRow(ControllerSpec{}); err == nil {
+		t.Fatal("A controller without target should be rejected")
+	}
+	if _, err := newControllerRow(ControllerSpec{Target: "tcp:127.0.0.1:6653", ConnectionMode: "inband"}); err == nil {
+		t.Fatal("An unknown connection mode should be rejected")
+	}
+	ctrler, err := newControllerRow(ControllerSpec{Target: "tcp:127.0.0.1:6653", MaxBackoff: 8000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctrler["max_backoff"] != 8000 {
+		t.Fatalf("The max backoff %v is incorrect", ctrler["max_backoff"])
+	}
+	if _, ok := ctrler["inactivity_probe"]; ok {
+		t.Fatal("The inactivity probe should be left to the default")
+	}
+}
+
+func TestUnreferencedControllers(t *testing.T) {
+	testClient := &ovsClient{bridgeCache: map[string]*OvsBridge{
+		"br1": {UUID: "br1", Name: "br1", ControllerUUIDs: []string{"c1", "c2"}},
+		"br2": {UUID: "br2", Name: "br2", ControllerUUIDs: []string{"c2"}},
+	}}
+	uuids := testClient.unreferencedControllers(testClient.bridgeCache["br1"])
+	if len(uuids) != 1 || uuids[0] != "c1" {
+		t.Fatalf("The unreferenced controllers %v are incorrect", uuids)
+	}
+}
+
+func TestControllerStatusReadFromDBRow(t *testing.T) {
+	controller := &OvsController{UUID: "abcde12345"}
+	status, _ := libovsdb.NewOvsMap(map[string]string{"state": "ACTIVE", "sec_since_connect": "42", "last_error": "Connection refused"})
+	row := &libovsdb.Row{Fields: map[string]interface{}{
+		"is_connected": true,
+		"role":         "master",
+		"status":       *status,
+	}}
+	if err := controller.ReadFromDBRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if !controller.IsConnected || controller.Role != "master" {
+		t.Fatalf("The controller %+v is incorrect", controller)
+	}
+	if controller.Status.State != "ACTIVE" || controller.Status.SecSinceConnect != 42 || controller.Status.LastError != "Connection refused" {
+		t.Fatalf("The controller status %+v is incorrect", controller.Status)
+	}
+}
+
+func TestWaitForControllerConnected(t *testing.T) {
+	testClient := &ovsClient{
+		bridgeCache:     map[string]*OvsBridge{"br1": {UUID: "br1", Name: "br1", ControllerUUIDs: []string{"c1"}}},
+		controllerCache: map[string]*OvsController{"c1": {UUID: "c1", Status: ControllerStatus{LastError: "Connection refused"}}},
+	}
+	if err := testClient.WaitForControllerConnected("br1", 0); err == nil {
+		t.Fatal("The controller should not be connected")
+	}
+	testClient.controllerCache["c1"].IsConnected = true
+	if err := testClient.WaitForControllerConnected("br1", time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetControllers(t *testing.T) {
+	// TODO
+}
+
+func TestDelController(t *testing.T) {
+	// TODO
+}
+
+func TestGetControllers(t *testing.T) {
+	// TODO
+}
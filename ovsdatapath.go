@@ -0,0 +1,216 @@
+package goovs
+
+import (
+	"fmt"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	// DatapathTypeSystem is the kernel datapath, used when none is set
+	DatapathTypeSystem = "system"
+	// DatapathTypeNetdev is the userspace datapath used with DPDK
+	DatapathTypeNetdev = "netdev"
+)
+
+const datapathTableName = "Datapath"
+
+// OvsDatapath is the structure represents a row of the Datapath table, which
+// ovs-vswitchd fills for each datapath type in use
+type OvsDatapath struct {
+	UUID            string            `json:"_uuid"`
+	Type            string            `json:"type"`
+	DatapathVersion string            `json:"datapath_version"`
+	Capabilities    map[string]string `json:"capabilities"`
+	ExternalIDs     map[string]string `json:"external_ids"`
+}
+
+// ReadFromDBRow is used to initialize the object from a row
+func (datapath *OvsDatapath) ReadFromDBRow(row *libovsdb.Row) error {
+	for field, value := range row.Fields {
+		switch field {
+		case "datapath_version":
+			datapath.DatapathVersion, _ = stringFromValue(value)
+		case "capabilities":
+			datapath.Capabilities = mapFromValue(value)
+		case "external_ids":
+			datapath.ExternalIDs = mapFromValue(value)
+		}
+	}
+	return nil
+}
+
+const (
+	dpdkInterfaceType                = "dpdk"
+	dpdkVhostUserInterfaceType       = "dpdkvhostuser"
+	dpdkVhostUserClientInterfaceType = "dpdkvhostuserclient"
+)
+
+// GetDatapathTypes returns the datapath types supported by ovs-vswitchd
+func (client *ovsClient) GetDatapathTypes() ([]string, error) {
+	return getRootStringSet("datapath_types")
+}
+
+// GetInterfaceTypes returns the interface types supported by ovs-vswitchd
+func (client *ovsClient) GetInterfaceTypes() ([]string, error) {
+	return getRootStringSet("iface_types")
+}
+
+// DatapathTypeSupported tells if ovs-vswitchd supports the datapath type
+func (client *ovsClient) DatapathTypeSupported(dptype string) (bool, error) {
+	dptypes, err := client.GetDatapathTypes()
+	if err != nil {
+		return false, err
+	}
+	for _, supported := range dptypes {
+		if supported == dptype {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateNetdevBridge creates a bridge using the userspace datapath
+func (client *ovsClient) CreateNetdevBridge(brname string) error {
+	return client.CreateBridgeWithSpec(BridgeSpec{Name: brname, DatapathType: DatapathTypeNetdev})
+}
+
+// CreateDpdkPort creates a port for a physical NIC bound to DPDK, devargs is
+// the PCI address or the vdev of the NIC
+func (client *ovsClient) CreateDpdkPort(brname, portname, devargs string, vlantag int) error {
+	return client.CreatePorts(brname, []PortSpec{{
+		Name:    portname,
+		Type:    dpdkInterfaceType,
+		VlanTag: vlantag,
+		Options: map[string]string{"dpdk-devargs": devargs},
+	}})
+}
+
+// CreateVhostUserPort creates a vhost-user port where ovs is the server
+func (client *ovsClient) CreateVhostUserPort(brname, portname string, vlantag int) error {
+	return client.CreatePorts(brname, []PortSpec{{
+		Name:    portname,
+		Type:    dpdkVhostUserInterfaceType,
+		VlanTag: vlantag,
+	}})
+}
+
+// CreateVhostUserClientPort creates a vhost-user port where ovs is the
+// client of the socket created by the VM
+func (client *ovsClient) CreateVhostUserClientPort(brname, portname, socketPath string, vlantag int) error {
+	return client.CreatePorts(brname, []PortSpec{{
+		Name:    portname,
+		Type:    dpdkVhostUserClientInterfaceType,
+		VlanTag: vlantag,
+		Options: map[string]string{"vhost-server-path": socketPath},
+	}})
+}
+
+// GetBridgeDatapath returns the datapath a bridge is linked to, through the
+// datapaths column of the Open_vSwitch row
+func (client *ovsClient) GetBridgeDatapath(brname string) (*OvsDatapath, error) {
+	dptype, err := client.getBridgeDatapathType(brname)
+	if err != nil {
+		return nil, err
+	}
+	dptype = datapathTypeName(dptype)
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	root, ok := cache[ovsTableName][getRootUUID()]
+	if !ok {
+		return nil, fmt.Errorf("The %s row doesn't exist", ovsTableName)
+	}
+	datapaths, ok := root.Fields["datapaths"].(libovsdb.OvsMap)
+	if !ok {
+		return nil, fmt.Errorf("The datapaths are not supported by the ovsdb schema")
+	}
+	for key, value := range datapaths.GoMap {
+		uuid, ok := value.(libovsdb.UUID)
+		if !ok || key != dptype {
+			continue
+		}
+		row, ok := cache[datapathTableName][uuid.GoUUID]
+		if !ok {
+			break
+		}
+		datapath := &OvsDatapath{UUID: uuid.GoUUID, Type: dptype}
+		datapath.ReadFromDBRow(&row)
+		return datapath, nil
+	}
+	return nil, fmt.Errorf("The %s datapath of bridge %s doesn't exist", dptype, brname)
+}
+
+// checkDatapathSupported verifies that ovs-vswitchd reports the datapath
+// type. ovs-vswitchd may not have filled the column yet, then the check passes
+func (client *ovsClient) checkDatapathSupported(dptype string) error {
+	if dptype == "" {
+		return nil
+	}
+	dptypes, err := client.GetDatapathTypes()
+	if err != nil || len(dptypes) == 0 {
+		return nil
+	}
+	for _, supported := range dptypes {
+		if supported == dptype {
+			return nil
+		}
+	}
+	return fmt.Errorf("The datapath type %s is not supported, the available types are %v", dptype, dptypes)
+}
+
+// interfaceTypeWarning returns a warning if an interface of the given type
+// can't work properly on a bridge using the datapath type
+func interfaceTypeWarning(dptype, intftype string) string {
+	switch intftype {
+	case dpdkInterfaceType, dpdkVhostUserInterfaceType, dpdkVhostUserClientInterfaceType:
+		if dptype != DatapathTypeNetdev {
+			return fmt.Sprintf("the %s interface requires the %s datapath but the bridge uses %s", intftype, DatapathTypeNetdev, datapathTypeName(dptype))
+		}
+	case "", "system":
+		if dptype == DatapathTypeNetdev {
+			return fmt.Sprintf("the system interface on the %s datapath is not accelerated, a dpdk interface may be expected", DatapathTypeNetdev)
+		}
+	}
+	return ""
+}
+
+func datapathTypeName(dptype string) string {
+	if dptype == "" {
+		return DatapathTypeSystem
+	}
+	return dptype
+}
+
+// warnInterfaceType reports a warning to the instrumentation if the interface
+// type doesn't suit the datapath of the bridge
+func (client *ovsClient) warnInterfaceType(brname, portname, intftype string) {
+	dptype, err := client.getBridgeDatapathType(brname)
+	if err != nil {
+		return
+	}
+	if warning := interfaceTypeWarning(dptype, intftype); warning != "" {
+		getInstrumentation().Warning(interfaceTypeWarningKind, fmt.Sprintf("port %s on bridge %s: %s", portname, brname, warning))
+	}
+}
+
+func (client *ovsClient) getBridgeDatapathType(brname string) (string, error) {
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	for _, bridge := range client.bridgeCache {
+		if bridge.Name == brname {
+			return bridge.DatapathType, nil
+		}
+	}
+	return "", fmt.Errorf("Bridge with name %s doesn't exist", brname)
+}
+
+// getRootStringSet reads a string set column of the Open_vSwitch row
+func getRootStringSet(column string) ([]string, error) {
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	row, ok := cache[ovsTableName][getRootUUID()]
+	if !ok {
+		return nil, fmt.Errorf("The %s row doesn't exist", ovsTableName)
+	}
+	return stringsFromValue(row.Fields[column]), nil
+}
@@ -0,0 +1,72 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestInterfaceTypeWarning(t *testing.T) {
+	if warning := interfaceTypeWarning("", dpdkInterfaceType); warning == "" {
+		t.Fatal("A dpdk interface on the system datapath should be warned")
+	}
+	if warning := interfaceTypeWarning(DatapathTypeNetdev, dpdkVhostUserInterfaceType); warning != "" {
+		t.Fatalf("A vhost-user interface on the netdev datapath should not be warned: %s", warning)
+	}
+	if warning := interfaceTypeWarning(DatapathTypeNetdev, "internal"); warning != "" {
+		t.Fatalf("An internal interface should not be warned: %s", warning)
+	}
+}
+
+type warningRecorder struct {
+	nopInstrumentation
+	kinds []string
+}
+
+func (recorder *warningRecorder) Warning(kind, message string) {
+	recorder.kinds = append(recorder.kinds, kind)
+}
+
+func TestWarnInterfaceType(t *testing.T) {
+	recorder := &warningRecorder{}
+	SetInstrumentation(recorder)
+	defer SetInstrumentation(nil)
+	testClient := &ovsClient{bridgeCache: map[string]*OvsBridge{
+		"br0": {UUID: "br0", Name: "br0", DatapathType: DatapathTypeSystem},
+	}}
+	testClient.warnInterfaceType("br0", "p1", "internal")
+	testClient.warnInterfaceType("br0", "p2", dpdkInterfaceType)
+	if len(recorder.kinds) != 1 || recorder.kinds[0] != interfaceTypeWarningKind {
+		t.Fatalf("One interface type warning should be reported, got %v", recorder.kinds)
+	}
+}
+
+func TestGetRootStringSet(t *testing.T) {
+	tmpCache := cache
+	defer func() { cache = tmpCache }()
+	cache = make(map[string]map[string]libovsdb.Row)
+	cache[defaultOvsDB] = make(map[string]libovsdb.Row)
+	cache[defaultOvsDB]["abcdef12345"] = libovsdb.Row{Fields: map[string]interface{}{
+		"datapath_types": libovsdb.OvsSet{GoSet: []interface{}{"netdev", "system"}},
+		"iface_types":    "internal",
+	}}
+	dptypes, err := getRootStringSet("datapath_types")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dptypes) != 2 || dptypes[0] != "netdev" {
+		t.Fatalf("The datapath types %v are incorrect", dptypes)
+	}
+	intftypes, _ := getRootStringSet("iface_types")
+	if len(intftypes) != 1 || intftypes[0] != "internal" {
+		t.Fatalf("The interface types %v are incorrect", intftypes)
+	}
+}
+
+func TestCreateNetdevBridge(t *testing.T) {
+	// TODO
+}
+
+func TestCreateDpdkPort(t *testing.T) {
+	// TODO
+}
@@ -0,0 +1,296 @@
+package goovs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+// fakeBridgeExternalIDPrefix prefixes the external_ids keys of a fake bridge,
+// which ovs-vsctl stores on the port of the fake bridge
+const fakeBridgeExternalIDPrefix = "fake-bridge-"
+
+// OvsFakeBridge is a VLAN pseudo bridge, i.e. an internal port tagged with
+// the VLAN on its parent bridge. The parent ports with the same tag belong to
+// the fake bridge
+type OvsFakeBridge struct {
+	Name        string            `json:"name"`
+	Parent      string            `json:"parent"`
+	VLAN        int               `json:"vlan"`
+	ExternalIDs map[string]string `json:"external_ids"`
+}
+
+// CreateFakeBridge creates the fake bridge name on the parent bridge for the
+// vlan, like ovs-vsctl add-br name parent vlan. The vlan 0 isn't supported
+// since the ports of the fake bridge couldn't be told from the untagged ones
+func (client *ovsClient) CreateFakeBridge(brname, parent string, vlan int) error {
+	if vlan < 1 || vlan > 4095 {
+		return fmt.Errorf("The vlan %d of fake bridge %s is invalid", vlan, brname)
+	}
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	if brname == parent {
+		return fmt.Errorf("The fake bridge %s can't be its own parent", brname)
+	}
+	if bridgeExists, err := client.bridgeRowExists(brname); err != nil {
+		return fmt.Errorf("Failed to retrieve the bridge info")
+	} else if bridgeExists {
+		return fmt.Errorf("The bridge %s already exists", brname)
+	}
+	if parentBridge, port, err := client.getFakeBridge(brname); err == nil {
+		if parentBridge.Name == parent && port.HasTag && int(port.Tag) == vlan {
+			return nil
+		}
+		return fmt.Errorf("The fake bridge %s already exists on bridge %s with vlan %d", brname, parentBridge.Name, int(port.Tag))
+	}
+	if _, err := client.getPortByName(brname); err == nil {
+		return fmt.Errorf("The port %s already exists", brname)
+	}
+	parentBridge, err := client.getBridgeByName(parent)
+	if err != nil {
+		return err
+	}
+	for _, uuid := range parentBridge.PortUUIDs {
+		if port, ok := client.getPortCopy(uuid); ok && port.FakeBridge && port.HasTag && int(port.Tag) == vlan {
+			return fmt.Errorf("The fake bridge %s already uses vlan %d on bridge %s", port.Name, vlan, parent)
+		}
+	}
+
+	namedPortUUID := "goport"
+	namedInterfaceUUID := "gointerface"
+
+	// intf row to insert
+	intf := make(map[string]interface{})
+	intf["name"] = brname
+	intf["type"] = `internal`
+
+	insertInterfaceOp := libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    interfaceTableName,
+		Row:      intf,
+		UUIDName: namedInterfaceUUID,
+	}
+
+	// port row to insert
+	port := make(map[string]interface{})
+	port["name"] = brname
+	port["interfaces"] = libovsdb.UUID{GoUUID: namedInterfaceUUID}
+	port["fake_bridge"] = true
+	port["tag"] = vlan
+
+	insertPortOp := libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    portTableName,
+		Row:      port,
+		UUIDName: namedPortUUID,
+	}
+
+	// Inserting a Port row in Port table requires mutating the Bridge table
+	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: namedPortUUID}}
+	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
+	mutation := libovsdb.NewMutation("ports", insertOperation, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", parent)
+
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     bridgeTableName,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+
+	operations := []libovsdb.Operation{insertInterfaceOp, insertPortOp, mutateOp}
+	return client.transact(operations, "create fake bridge")
+}
+
+// DeleteFakeBridge deletes a fake bridge along with the ports which belong
+// to it, like ovs-vsctl del-br
+func (client *ovsClient) DeleteFakeBridge(brname string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	parent, fakePort, err := client.getFakeBridge(brname)
+	if err != nil {
+		return nil
+	}
+	_, err = client.deleteFakeBridgeLocked(parent, fakePort)
+	return err
+}
+
+// deleteFakeBridgeLocked deletes the fake bridge of fakePort on the parent
+// and reports the removed ports and interfaces. The caller must hold
+// bridgeUpdateLock
+func (client *ovsClient) deleteFakeBridgeLocked(parent *OvsBridge, fakePort *OvsPort) (*BridgeDeleteReport, error) {
+	portUUIDs := client.fakeBridgePortUUIDs(parent, fakePort)
+	report := &BridgeDeleteReport{
+		Bridge:                  fakePort.Name,
+		Ports:                   make([]string, 0, len(portUUIDs)),
+		Interfaces:              make([]string, 0),
+		Controllers:             make([]string, 0),
+		Mirrors:                 make([]string, 0),
+		FlowTables:              make([]int, 0),
+		FlowSampleCollectorSets: make([]int, 0),
+	}
+
+	var operations []libovsdb.Operation
+	mutateUUIDs := make([]libovsdb.UUID, 0, len(portUUIDs))
+	for _, portUUID := range portUUIDs {
+		if port, ok := client.getPortCopy(portUUID); ok {
+			report.Ports = append(report.Ports, port.Name)
+			for _, intfUUID := range port.IntfUUIDs {
+				intfCacheUpdateLock.RLock()
+				if intf, ok := client.interfaceCache[intfUUID]; ok {
+					report.Interfaces = append(report.Interfaces, intf.Name)
+				}
+				intfCacheUpdateLock.RUnlock()
+				operations = append(operations, libovsdb.Operation{
+					Op:    deleteOperation,
+					Table: interfaceTableName,
+					Where: []interface{}{libovsdb.NewCondition("_uuid", "==", []string{"uuid", intfUUID})},
+				})
+			}
+		}
+		operations = append(operations, libovsdb.Operation{
+			Op:    deleteOperation,
+			Table: portTableName,
+			Where: []interface{}{libovsdb.NewCondition("_uuid", "==", []string{"uuid", portUUID})},
+		})
+		mutateUUIDs = append(mutateUUIDs, libovsdb.UUID{GoUUID: portUUID})
+	}
+
+	// Deleting the Port rows requires mutating the parent in Bridge table
+	mutateSet, _ := libovsdb.NewOvsSet(mutateUUIDs)
+	mutation := libovsdb.NewMutation("ports", deleteOperation, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", parent.Name)
+	operations = append(operations, libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     bridgeTableName,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	})
+	if err := client.transact(operations, "delete fake bridge"); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetFakeBridge returns the parent, the vlan and the external_ids of a fake
+// bridge. The external_ids are read from the fake-bridge-* keys of its port
+func (client *ovsClient) GetFakeBridge(brname string) (*OvsFakeBridge, error) {
+	parent, port, err := client.getFakeBridge(brname)
+	if err != nil {
+		return nil, err
+	}
+	fakeBridge := &OvsFakeBridge{
+		Name:        brname,
+		Parent:      parent.Name,
+		VLAN:        int(port.Tag),
+		ExternalIDs: make(map[string]string),
+	}
+	for key, value := range port.ExternalIDs {
+		if strings.HasPrefix(key, fakeBridgeExternalIDPrefix) {
+			fakeBridge.ExternalIDs[strings.TrimPrefix(key, fakeBridgeExternalIDPrefix)] = value
+		}
+	}
+	return fakeBridge, nil
+}
+
+// resolvePortBridge returns the bridge row holding the ports of brname and
+// the vlan tag of a port created on it. The ports of a fake bridge are held
+// by its parent and tagged with its vlan, like ovs-vsctl add-port does
+func (client *ovsClient) resolvePortBridge(brname string, vlantag int) (string, int, error) {
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil {
+		return "", 0, fmt.Errorf("Failed to retrieve the bridge info")
+	} else if bridgeExists {
+		return brname, vlantag, nil
+	}
+	parent, fakePort, err := client.getFakeBridge(brname)
+	if err != nil {
+		return "", 0, fmt.Errorf("The bridge %s doesn't exist", brname)
+	}
+	fakeVlan := 0
+	if fakePort.HasTag {
+		fakeVlan = int(fakePort.Tag)
+	}
+	if vlantag != 0 && vlantag != fakeVlan {
+		return "", 0, fmt.Errorf("The port on fake bridge %s can't use vlan %d since the fake bridge uses vlan %d", brname, vlantag, fakeVlan)
+	}
+	return parent.Name, fakeVlan, nil
+}
+
+// getFakeBridge returns the parent bridge and the port of a fake bridge
+func (client *ovsClient) getFakeBridge(brname string) (*OvsBridge, *OvsPort, error) {
+	port, err := client.getPortByName(brname)
+	if err != nil || !port.FakeBridge {
+		return nil, nil, fmt.Errorf("The fake bridge %s doesn't exist", brname)
+	}
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	for _, bridge := range client.bridgeCache {
+		for _, uuid := range bridge.PortUUIDs {
+			if uuid == port.UUID {
+				brObj := *bridge
+				return &brObj, port, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("The parent bridge of fake bridge %s doesn't exist", brname)
+}
+
+// fakeBridgePortUUIDs returns the port of the fake bridge and the ports of
+// the parent tagged with its vlan, which ovs-vsctl considers as the ports of
+// the fake bridge. A fake bridge without tag has no other port
+func (client *ovsClient) fakeBridgePortUUIDs(parent *OvsBridge, fakePort *OvsPort) []string {
+	portCacheUpdateLock.RLock()
+	defer portCacheUpdateLock.RUnlock()
+	uuids := make([]string, 0)
+	for _, uuid := range parent.PortUUIDs {
+		port, ok := client.portCache[uuid]
+		if !ok {
+			continue
+		}
+		if uuid == fakePort.UUID || (fakePort.HasTag && port.HasTag && !port.FakeBridge && port.Tag == fakePort.Tag) {
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
+
+// excludeFakeBridgePorts removes the ports belonging to the fake bridges of
+// the parent from the port list
+func (client *ovsClient) excludeFakeBridgePorts(portUUIDs []string) []string {
+	portCacheUpdateLock.RLock()
+	defer portCacheUpdateLock.RUnlock()
+	fakeBridges := false
+	fakeVLANs := make(map[float64]bool)
+	for _, uuid := range portUUIDs {
+		if port, ok := client.portCache[uuid]; ok && port.FakeBridge {
+			fakeBridges = true
+			if port.HasTag {
+				fakeVLANs[port.Tag] = true
+			}
+		}
+	}
+	if !fakeBridges {
+		return portUUIDs
+	}
+	uuids := make([]string, 0, len(portUUIDs))
+	for _, uuid := range portUUIDs {
+		if port, ok := client.portCache[uuid]; ok && (port.FakeBridge || (port.HasTag && fakeVLANs[port.Tag])) {
+			continue
+		}
+		uuids = append(uuids, uuid)
+	}
+	return uuids
+}
+
+func (client *ovsClient) getPortCopy(uuid string) (*OvsPort, bool) {
+	portCacheUpdateLock.RLock()
+	defer portCacheUpdateLock.RUnlock()
+	port, ok := client.portCache[uuid]
+	if !ok {
+		return nil, false
+	}
+	portObj := *port
+	return &portObj, true
+}
@@ -0,0 +1,175 @@
+package goovs
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newFakeBridgeTestClient() *ovsClient {
+	return &ovsClient{
+		bridgeCache: map[string]*OvsBridge{
+			"br0": {UUID: "br0", Name: "br0", PortUUIDs: []string{"p0", "p1", "p2", "p3"}},
+		},
+		portCache: map[string]*OvsPort{
+			"p0": {UUID: "p0", Name: "br0"},
+			"p1": {UUID: "p1", Name: "br10", Tag: 10, HasTag: true, FakeBridge: true, ExternalIDs: map[string]string{"fake-bridge-owner": "legacy", "other": "x"}},
+			"p2": {UUID: "p2", Name: "vm1", Tag: 10, HasTag: true},
+			"p3": {UUID: "p3", Name: "vm2", Tag: 20, HasTag: true},
+		},
+	}
+}
+
+func TestFakeBridgeExists(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	for _, brname := range []string{"br0", "br10"} {
+		if exists, _ := testClient.BridgeExists(brname); !exists {
+			t.Fatalf("The bridge %s should exist", brname)
+		}
+	}
+	if exists, _ := testClient.BridgeExists("vm1"); exists {
+		t.Fatal("The port vm1 is not a fake bridge")
+	}
+	fakeBridge, err := testClient.GetFakeBridge("br10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakeBridge.Parent != "br0" || fakeBridge.VLAN != 10 || len(fakeBridge.ExternalIDs) != 1 || fakeBridge.ExternalIDs["owner"] != "legacy" {
+		t.Fatalf("The fake bridge %+v is incorrect", fakeBridge)
+	}
+}
+
+func TestFindAllPortsOnFakeBridge(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	ports, err := testClient.FindAllPortsOnBridge("br10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ports)
+	if len(ports) != 2 || ports[0] != "br10" || ports[1] != "vm1" {
+		t.Fatalf("The ports %v of the fake bridge are incorrect", ports)
+	}
+	ports, err = testClient.FindAllPortsOnBridge("br0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ports)
+	if len(ports) != 2 || ports[0] != "br0" || ports[1] != "vm2" {
+		t.Fatalf("The ports %v of the parent bridge are incorrect", ports)
+	}
+}
+
+func TestCreateFakeBridge(t *testing.T) {
+	// TODO
+}
+
+func TestDeleteFakeBridge(t *testing.T) {
+	// TODO
+}
+
+func TestFindAllPortsOnUntaggedFakeBridge(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	testClient.bridgeCache["br0"].PortUUIDs = append(testClient.bridgeCache["br0"].PortUUIDs, "p4", "p5")
+	testClient.portCache["p4"] = &OvsPort{UUID: "p4", Name: "br0-fake", FakeBridge: true}
+	testClient.portCache["p5"] = &OvsPort{UUID: "p5", Name: "vm3"}
+	ports, err := testClient.FindAllPortsOnBridge("br0-fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 1 || ports[0] != "br0-fake" {
+		t.Fatalf("The untagged fake bridge should not claim the untagged ports, got %v", ports)
+	}
+	ports, err = testClient.FindAllPortsOnBridge("br0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ports)
+	if len(ports) != 3 || ports[0] != "br0" || ports[1] != "vm2" || ports[2] != "vm3" {
+		t.Fatalf("The untagged ports should stay on the parent bridge, got %v", ports)
+	}
+}
+
+func TestPortExistsOnFakeBridge(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	if exists, err := testClient.PortExistsOnBridge("vm1", "br10"); err != nil || !exists {
+		t.Fatalf("The port vm1 should exist on the fake bridge, got %v, %v", exists, err)
+	}
+	if exists, err := testClient.PortExistsOnBridge("vm2", "br10"); err != nil || exists {
+		t.Fatalf("The port vm2 should not exist on the fake bridge, got %v, %v", exists, err)
+	}
+	if _, err := testClient.PortExistsOnBridge("vm1", "br99"); err == nil {
+		t.Fatal("The unknown bridge should be reported")
+	}
+}
+
+func TestResolvePortBridge(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	brname, vlantag, err := testClient.resolvePortBridge("br10", 0)
+	if err != nil || brname != "br0" || vlantag != 10 {
+		t.Fatalf("The fake bridge should resolve to br0 with vlan 10, got %s, %d, %v", brname, vlantag, err)
+	}
+	if _, _, err = testClient.resolvePortBridge("br10", 20); err == nil {
+		t.Fatal("A vlan other than the one of the fake bridge should be rejected")
+	}
+	brname, vlantag, err = testClient.resolvePortBridge("br0", 20)
+	if err != nil || brname != "br0" || vlantag != 20 {
+		t.Fatalf("The bridge br0 should be kept, got %s, %d, %v", brname, vlantag, err)
+	}
+	if _, _, err = testClient.resolvePortBridge("br99", 0); err == nil {
+		t.Fatal("The unknown bridge should be reported")
+	}
+}
+
+func TestCreatePortsOnFakeBridge(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	transactions, release := newFakeTransactClient(t, testClient)
+	defer release()
+
+	if err := testClient.CreatePorts("br10", []PortSpec{{Name: "vm5", VlanTag: 20}}); err == nil {
+		t.Fatal("A vlan other than the one of the fake bridge should be rejected")
+	}
+	if err := testClient.CreatePorts("br10", []PortSpec{{Name: "vm5"}}); err != nil {
+		t.Fatal(err)
+	}
+	var operations []map[string]interface{}
+	select {
+	case operations = <-transactions:
+	case <-time.After(time.Second):
+		t.Fatal("No transaction is sent")
+	}
+	if len(operations) != 3 {
+		t.Fatalf("The operations %v are incorrect", operations)
+	}
+	port, _ := operations[1]["row"].(map[string]interface{})
+	if operations[1]["table"] != portTableName || port["tag"] != float64(10) {
+		t.Fatalf("The port should be tagged with the vlan of the fake bridge, got %v", operations[1])
+	}
+	where, _ := operations[2]["where"].([]interface{})
+	if operations[2]["table"] != bridgeTableName || len(where) != 1 || !reflect.DeepEqual(where[0], []interface{}{"name", "==", "br0"}) {
+		t.Fatalf("The parent bridge should be mutated, got %v", operations[2])
+	}
+}
+
+func TestPortExistsOnParentBridge(t *testing.T) {
+	testClient := newFakeBridgeTestClient()
+	for _, brname := range []string{"br0", "br10"} {
+		ports, err := testClient.FindAllPortsOnBridge(brname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, portname := range []string{"br0", "br10", "vm1", "vm2"} {
+			exists, err := testClient.PortExistsOnBridge(portname, brname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := false
+			for _, name := range ports {
+				listed = listed || name == portname
+			}
+			if exists != listed {
+				t.Fatalf("The port %s on bridge %s exists %v but is listed %v", portname, brname, exists, listed)
+			}
+		}
+	}
+}
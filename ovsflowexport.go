@@ -0,0 +1,361 @@
+package goovs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	netflowTableName             = "NetFlow"
+	sflowTableName               = "sFlow"
+	ipfixTableName               = "IPFIX"
+	flowSampleCollectorTableName = "Flow_Sample_Collector_Set"
+)
+
+// NetFlowSpec describes the NetFlow export of a bridge. The export row is
+// replaced as a whole, the zero engine fields are left out of it.
+// ActiveTimeout is in seconds, -1 disables it
+type NetFlowSpec struct {
+	Targets          []string
+	EngineType       int
+	EngineID         int
+	AddIDToInterface bool
+	ActiveTimeout    int
+	ExternalIDs      map[string]string
+}
+
+// SFlowSpec describes the sFlow export of a bridge, the zero sampling,
+// polling and header aren't written. Agent is the interface or address
+// identifying the switch
+type SFlowSpec struct {
+	Targets     []string
+	Sampling    int
+	Polling     int
+	Header      int
+	Agent       string
+	ExternalIDs map[string]string
+}
+
+// IPFIXSpec describes an IPFIX exporter, either bridge wide or used by a
+// flow sample collector set. A new row is inserted on every change, without
+// the zero sampling, ids and cache limits
+type IPFIXSpec struct {
+	Targets            []string
+	Sampling           int
+	ObsDomainID        int
+	ObsPointID         int
+	CacheActiveTimeout int
+	CacheMaxFlows      int
+	OtherConfig        map[string]string
+	ExternalIDs        map[string]string
+}
+
+// FlowExport holds the flow exports attached to a bridge, nil if not set
+type FlowExport struct {
+	NetFlow *NetFlowSpec
+	SFlow   *SFlowSpec
+	IPFIX   *IPFIXSpec
+}
+
+// SetBridgeNetFlow attaches a NetFlow export to a bridge, replacing the
+// current one
+func (client *ovsClient) SetBridgeNetFlow(brname string, spec NetFlowSpec) error {
+	row, err := newNetFlowRow(spec)
+	if err != nil {
+		return err
+	}
+	return client.setBridgeReference(brname, "netflow", netflowTableName, row, "set bridge netflow")
+}
+
+// ClearBridgeNetFlow detaches the NetFlow export of a bridge
+func (client *ovsClient) ClearBridgeNetFlow(brname string) error {
+	return client.setBridgeReference(brname, "netflow", netflowTableName, nil, "clear bridge netflow")
+}
+
+// SetBridgeSFlow attaches an sFlow export to a bridge, replacing the current
+// one
+func (client *ovsClient) SetBridgeSFlow(brname string, spec SFlowSpec) error {
+	row, err := newSFlowRow(spec)
+	if err != nil {
+		return err
+	}
+	return client.setBridgeReference(brname, "sflow", sflowTableName, row, "set bridge sflow")
+}
+
+// ClearBridgeSFlow detaches the sFlow export of a bridge
+func (client *ovsClient) ClearBridgeSFlow(brname string) error {
+	return client.setBridgeReference(brname, "sflow", sflowTableName, nil, "clear bridge sflow")
+}
+
+// SetBridgeIPFIX attaches a bridge wide IPFIX export to a bridge, replacing
+// the current one
+func (client *ovsClient) SetBridgeIPFIX(brname string, spec IPFIXSpec) error {
+	if len(spec.Targets) == 0 {
+		return fmt.Errorf("The IPFIX export of bridge %s has no target", brname)
+	}
+	row, err := newIPFIXRow(spec)
+	if err != nil {
+		return err
+	}
+	return client.setBridgeReference(brname, "ipfix", ipfixTableName, row, "set bridge ipfix")
+}
+
+// ClearBridgeIPFIX detaches the bridge wide IPFIX export of a bridge
+func (client *ovsClient) ClearBridgeIPFIX(brname string) error {
+	return client.setBridgeReference(brname, "ipfix", ipfixTableName, nil, "clear bridge ipfix")
+}
+
+// GetBridgeFlowExport returns the flow exports attached to a bridge
+func (client *ovsClient) GetBridgeFlowExport(brname string) (FlowExport, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return FlowExport{}, err
+	}
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	export := FlowExport{}
+	if row, ok := cache[netflowTableName][bridge.NetFlowUUID]; ok {
+		export.NetFlow = readNetFlowRow(&row)
+	}
+	if row, ok := cache[sflowTableName][bridge.SFlowUUID]; ok {
+		export.SFlow = readSFlowRow(&row)
+	}
+	if row, ok := cache[ipfixTableName][bridge.IPFIXUUID]; ok {
+		export.IPFIX = readIPFIXRow(&row)
+	}
+	return export, nil
+}
+
+// AddFlowSampleCollectorSet adds the collector set with the id to a bridge,
+// so that the sample actions of the flows using the id export IPFIX records
+// to the targets of the spec. An existing set with the same id is replaced
+func (client *ovsClient) AddFlowSampleCollectorSet(brname string, id int, spec IPFIXSpec) error {
+	if id < 0 || int64(id) > math.MaxUint32 {
+		return fmt.Errorf("The collector set id %d is invalid", id)
+	}
+	ipfix, err := newIPFIXRow(spec)
+	if err != nil {
+		return err
+	}
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return err
+	}
+
+	namedIPFIXUUID := "goipfix"
+	operations := client.deleteCollectorSetOperations(bridge.UUID, id)
+	operations = append(operations, libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    ipfixTableName,
+		Row:      ipfix,
+		UUIDName: namedIPFIXUUID,
+	})
+	collectorSet := make(map[string]interface{})
+	collectorSet["id"] = id
+	collectorSet["bridge"] = libovsdb.UUID{GoUUID: bridge.UUID}
+	collectorSet["ipfix"] = libovsdb.UUID{GoUUID: namedIPFIXUUID}
+	operations = append(operations, libovsdb.Operation{
+		Op:    insertOperation,
+		Table: flowSampleCollectorTableName,
+		Row:   collectorSet,
+	})
+	return client.transact(operations, "add flow sample collector set")
+}
+
+// DeleteFlowSampleCollectorSet removes the collector set with the id from a
+// bridge
+func (client *ovsClient) DeleteFlowSampleCollectorSet(brname string, id int) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return err
+	}
+	operations := client.deleteCollectorSetOperations(bridge.UUID, id)
+	if len(operations) == 0 {
+		return nil
+	}
+	return client.transact(operations, "delete flow sample collector set")
+}
+
+// deleteCollectorSetOperations returns the operations deleting the collector
+// sets of the bridge with the id, the IPFIX rows are garbage collected
+func (client *ovsClient) deleteCollectorSetOperations(bridgeUUID string, id int) []libovsdb.Operation {
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	var operations []libovsdb.Operation
+	for uuid, row := range cache[flowSampleCollectorTableName] {
+		setID, _ := intFromValue(row.Fields["id"])
+		bridges := uuidsFromValue(row.Fields["bridge"])
+		if setID != id || len(bridges) != 1 || bridges[0] != bridgeUUID {
+			continue
+		}
+		operations = append(operations, libovsdb.Operation{
+			Op:    deleteOperation,
+			Table: flowSampleCollectorTableName,
+			Where: []interface{}{libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUUID: uuid})},
+		})
+	}
+	return operations
+}
+
+// setBridgeReference inserts the row and points the bridge column to it, a
+// nil row clears the column. The replaced row is garbage collected
+func (client *ovsClient) setBridgeReference(brname, column, table string, row map[string]interface{}, action string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the bridge info")
+	} else if !bridgeExists {
+		return fmt.Errorf("The bridge %s doesn't exist", brname)
+	}
+
+	var operations []libovsdb.Operation
+	bridge := make(map[string]interface{})
+	if row == nil {
+		bridge[column], _ = libovsdb.NewOvsSet([]libovsdb.UUID{})
+	} else {
+		namedUUID := "goflowexport"
+		operations = append(operations, libovsdb.Operation{
+			Op:       insertOperation,
+			Table:    table,
+			Row:      row,
+			UUIDName: namedUUID,
+		})
+		bridge[column] = libovsdb.UUID{GoUUID: namedUUID}
+	}
+	operations = append(operations, libovsdb.Operation{
+		Op:    updateOperation,
+		Table: bridgeTableName,
+		Row:   bridge,
+		Where: []interface{}{libovsdb.NewCondition("name", "==", brname)},
+	})
+	return client.transact(operations, action)
+}
+
+func newNetFlowRow(spec NetFlowSpec) (map[string]interface{}, error) {
+	if len(spec.Targets) == 0 {
+		return nil, fmt.Errorf("The NetFlow export has no target")
+	}
+	row := make(map[string]interface{})
+	row["targets"], _ = libovsdb.NewOvsSet(spec.Targets)
+	row["add_id_to_interface"] = spec.AddIDToInterface
+	if err := setOptionalInt(row, "engine_type", spec.EngineType, 0, 255); err != nil {
+		return nil, err
+	}
+	if err := setOptionalInt(row, "engine_id", spec.EngineID, 0, 255); err != nil {
+		return nil, err
+	}
+	if spec.ActiveTimeout < -1 {
+		return nil, fmt.Errorf("The active_timeout value %d is invalid", spec.ActiveTimeout)
+	} else if spec.ActiveTimeout != 0 {
+		row["active_timeout"] = spec.ActiveTimeout
+	}
+	if len(spec.ExternalIDs) != 0 {
+		row["external_ids"], _ = libovsdb.NewOvsMap(spec.ExternalIDs)
+	}
+	return row, nil
+}
+
+func newSFlowRow(spec SFlowSpec) (map[string]interface{}, error) {
+	if len(spec.Targets) == 0 {
+		return nil, fmt.Errorf("The sFlow export has no target")
+	}
+	row := make(map[string]interface{})
+	row["targets"], _ = libovsdb.NewOvsSet(spec.Targets)
+	if err := setOptionalInt(row, "sampling", spec.Sampling, 1, math.MaxInt32); err != nil {
+		return nil, err
+	}
+	if err := setOptionalInt(row, "polling", spec.Polling, 1, math.MaxInt32); err != nil {
+		return nil, err
+	}
+	if err := setOptionalInt(row, "header", spec.Header, 1, math.MaxInt32); err != nil {
+		return nil, err
+	}
+	if spec.Agent != "" {
+		row["agent"] = spec.Agent
+	}
+	if len(spec.ExternalIDs) != 0 {
+		row["external_ids"], _ = libovsdb.NewOvsMap(spec.ExternalIDs)
+	}
+	return row, nil
+}
+
+func newIPFIXRow(spec IPFIXSpec) (map[string]interface{}, error) {
+	row := make(map[string]interface{})
+	row["targets"], _ = libovsdb.NewOvsSet(spec.Targets)
+	settings := []intSetting{
+		{"sampling", nonZeroInt(spec.Sampling), 1, math.MaxUint32},
+		{"obs_domain_id", nonZeroInt(spec.ObsDomainID), 0, math.MaxUint32},
+		{"obs_point_id", nonZeroInt(spec.ObsPointID), 0, math.MaxUint32},
+		{"cache_active_timeout", nonZeroInt(spec.CacheActiveTimeout), 0, 4200},
+		{"cache_max_flows", nonZeroInt(spec.CacheMaxFlows), 0, math.MaxUint32},
+	}
+	for _, setting := range settings {
+		if setting.value == nil {
+			continue
+		}
+		if err := setOptionalInt(row, setting.key, *setting.value, setting.min, setting.max); err != nil {
+			return nil, err
+		}
+	}
+	if len(spec.OtherConfig) != 0 {
+		row["other_config"], _ = libovsdb.NewOvsMap(spec.OtherConfig)
+	}
+	if len(spec.ExternalIDs) != 0 {
+		row["external_ids"], _ = libovsdb.NewOvsMap(spec.ExternalIDs)
+	}
+	return row, nil
+}
+
+// setOptionalInt sets an optional integer column, zero values are skipped
+func setOptionalInt(row map[string]interface{}, column string, value int, min, max int64) error {
+	if value == 0 {
+		return nil
+	}
+	if int64(value) < min || int64(value) > max {
+		return fmt.Errorf("The %s value %d is out of range [%d, %d]", column, value, min, max)
+	}
+	row[column] = value
+	return nil
+}
+
+func readNetFlowRow(row *libovsdb.Row) *NetFlowSpec {
+	spec := &NetFlowSpec{}
+	spec.Targets = stringsFromValue(row.Fields["targets"])
+	spec.EngineType, _ = intFromValue(row.Fields["engine_type"])
+	spec.EngineID, _ = intFromValue(row.Fields["engine_id"])
+	spec.AddIDToInterface, _ = boolFromValue(row.Fields["add_id_to_interface"])
+	spec.ActiveTimeout, _ = intFromValue(row.Fields["active_timeout"])
+	spec.ExternalIDs = mapFromValue(row.Fields["external_ids"])
+	return spec
+}
+
+func readSFlowRow(row *libovsdb.Row) *SFlowSpec {
+	spec := &SFlowSpec{}
+	spec.Targets = stringsFromValue(row.Fields["targets"])
+	spec.Sampling, _ = intFromValue(row.Fields["sampling"])
+	spec.Polling, _ = intFromValue(row.Fields["polling"])
+	spec.Header, _ = intFromValue(row.Fields["header"])
+	spec.Agent, _ = stringFromValue(row.Fields["agent"])
+	spec.ExternalIDs = mapFromValue(row.Fields["external_ids"])
+	return spec
+}
+
+func readIPFIXRow(row *libovsdb.Row) *IPFIXSpec {
+	spec := &IPFIXSpec{}
+	spec.Targets = stringsFromValue(row.Fields["targets"])
+	spec.Sampling, _ = intFromValue(row.Fields["sampling"])
+	spec.ObsDomainID, _ = intFromValue(row.Fields["obs_domain_id"])
+	spec.ObsPointID, _ = intFromValue(row.Fields["obs_point_id"])
+	spec.CacheActiveTimeout, _ = intFromValue(row.Fields["cache_active_timeout"])
+	spec.CacheMaxFlows, _ = intFromValue(row.Fields["cache_max_flows"])
+	spec.OtherConfig = mapFromValue(row.Fields["other_config"])
+	spec.ExternalIDs = mapFromValue(row.Fields["external_ids"])
+	return spec
+}
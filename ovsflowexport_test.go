@@ -0,0 +1,53 @@
+package goovs
+
+import (
+	"testing"
+)
+
+func TestNewNetFlowRow(t *testing.T) {
+	row, err := newNetFlowRow(NetFlowSpec{Targets: []string{"10.0.0.1:2055"}, EngineID: 7, ActiveTimeout: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["engine_id"] != 7 || row["active_timeout"] != -1 {
+		t.Fatalf("The netflow row %v is incorrect", row)
+	}
+	if _, ok := row["engine_type"]; ok {
+		t.Fatal("The engine_type should be left to the ovs default")
+	}
+	if _, err = newNetFlowRow(NetFlowSpec{}); err == nil {
+		t.Fatal("The netflow export without target should be invalid")
+	}
+	if _, err = newNetFlowRow(NetFlowSpec{Targets: []string{"10.0.0.1:2055"}, EngineType: 256}); err == nil {
+		t.Fatal("The engine_type 256 should be invalid")
+	}
+}
+
+func TestNewIPFIXRow(t *testing.T) {
+	row, err := newIPFIXRow(IPFIXSpec{Targets: []string{"10.0.0.1:4739"}, Sampling: 64, CacheActiveTimeout: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["sampling"] != 64 || row["cache_active_timeout"] != 60 {
+		t.Fatalf("The ipfix row %v is incorrect", row)
+	}
+	if _, err = newIPFIXRow(IPFIXSpec{CacheActiveTimeout: 5000}); err == nil {
+		t.Fatal("The cache_active_timeout 5000 should be invalid")
+	}
+}
+
+func TestSetBridgeNetFlow(t *testing.T) {
+	// TODO
+}
+
+func TestSetBridgeSFlow(t *testing.T) {
+	// TODO
+}
+
+func TestSetBridgeIPFIX(t *testing.T) {
+	// TODO
+}
+
+func TestAddFlowSampleCollectorSet(t *testing.T) {
+	// TODO
+}
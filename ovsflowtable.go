@@ -0,0 +1,193 @@
+package goovs
+
+import (
+	"fmt"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	// OverflowPolicyRefuse rejects new flows once the flow limit is reached
+	OverflowPolicyRefuse = "refuse"
+	// OverflowPolicyEvict removes the flows chosen by the groups to make room
+	// for new flows
+	OverflowPolicyEvict = "evict"
+)
+
+const (
+	maxFlowTableID  = 254
+	maxFlowPrefixes = 3
+)
+
+// OvsFlowTable is the structure represents a Flow_Table row
+type OvsFlowTable struct {
+	UUID           string            `json:"_uuid"`
+	Name           string            `json:"name"`
+	FlowLimit      int               `json:"flow_limit"`
+	OverflowPolicy string            `json:"overflow_policy"`
+	Groups         []string          `json:"groups"`
+	Prefixes       []string          `json:"prefixes"`
+	ExternalIDs    map[string]string `json:"external_ids"`
+}
+
+// FlowTableSpec describes the settings of an OpenFlow table, which replace
+// the previous ones. A zero flow limit means no limit, Groups are the fields
+// used to pick the flows to evict
+type FlowTableSpec struct {
+	Name           string
+	FlowLimit      int
+	OverflowPolicy string
+	Groups         []string
+	Prefixes       []string
+	ExternalIDs    map[string]string
+}
+
+// ReadFromDBRow is used to initialize the object from a row
+func (table *OvsFlowTable) ReadFromDBRow(row *libovsdb.Row) error {
+	for field, value := range row.Fields {
+		switch field {
+		case "name":
+			table.Name, _ = stringFromValue(value)
+		case "flow_limit":
+			table.FlowLimit, _ = intFromValue(value)
+		case "overflow_policy":
+			table.OverflowPolicy, _ = stringFromValue(value)
+		case "groups":
+			table.Groups = stringsFromValue(value)
+		case "prefixes":
+			table.Prefixes = stringsFromValue(value)
+		case "external_ids":
+			table.ExternalIDs = mapFromValue(value)
+		}
+	}
+	return nil
+}
+
+// SetBridgeFlowTable configures the OpenFlow table with the id on a bridge,
+// replacing its current settings
+func (client *ovsClient) SetBridgeFlowTable(brname string, tableID int, spec FlowTableSpec) error {
+	if err := validateFlowTableID(tableID); err != nil {
+		return err
+	}
+	table, err := newFlowTableRow(spec)
+	if err != nil {
+		return err
+	}
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the bridge info")
+	} else if !bridgeExists {
+		return fmt.Errorf("The bridge %s doesn't exist", brname)
+	}
+
+	namedFlowTableUUID := "goflowtable"
+	insertFlowTableOp := libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    flowTableName,
+		Row:      table,
+		UUIDName: namedFlowTableUUID,
+	}
+
+	// The table id is removed first since inserting an existing key doesn't
+	// replace its row, the replaced row is garbage collected
+	keySet, _ := libovsdb.NewOvsSet([]int{tableID})
+	tableMap, _ := libovsdb.NewOvsMap(map[int]libovsdb.UUID{tableID: {GoUUID: namedFlowTableUUID}})
+	mutateOp := libovsdb.Operation{
+		Op:    mutateOperation,
+		Table: bridgeTableName,
+		Mutations: []interface{}{
+			libovsdb.NewMutation("flow_tables", deleteOperation, keySet),
+			libovsdb.NewMutation("flow_tables", insertOperation, tableMap),
+		},
+		Where: []interface{}{libovsdb.NewCondition("name", "==", brname)},
+	}
+
+	operations := []libovsdb.Operation{insertFlowTableOp, mutateOp}
+	return client.transact(operations, "set bridge flow table")
+}
+
+// ClearBridgeFlowTable restores the default settings of the OpenFlow table
+// with the id on a bridge
+func (client *ovsClient) ClearBridgeFlowTable(brname string, tableID int) error {
+	if err := validateFlowTableID(tableID); err != nil {
+		return err
+	}
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridgeExists, err := client.bridgeRowExists(brname)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the bridge info")
+	} else if !bridgeExists {
+		return fmt.Errorf("The bridge %s doesn't exist", brname)
+	}
+	keySet, _ := libovsdb.NewOvsSet([]int{tableID})
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     bridgeTableName,
+		Mutations: []interface{}{libovsdb.NewMutation("flow_tables", deleteOperation, keySet)},
+		Where:     []interface{}{libovsdb.NewCondition("name", "==", brname)},
+	}
+	return client.transact([]libovsdb.Operation{mutateOp}, "clear bridge flow table")
+}
+
+// GetBridgeFlowTables returns the configured OpenFlow tables of a bridge by
+// table id
+func (client *ovsClient) GetBridgeFlowTables(brname string) (map[int]OvsFlowTable, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return nil, err
+	}
+	flowTableCacheUpdateLock.RLock()
+	defer flowTableCacheUpdateLock.RUnlock()
+	tables := make(map[int]OvsFlowTable, len(bridge.FlowTableUUIDs))
+	for tableID, uuid := range bridge.FlowTableUUIDs {
+		if table, ok := client.flowTableCache[uuid]; ok {
+			tables[tableID] = *table
+		}
+	}
+	return tables, nil
+}
+
+func validateFlowTableID(tableID int) error {
+	if tableID < 0 || tableID > maxFlowTableID {
+		return fmt.Errorf("The flow table id %d is invalid, it should be within [0, %d]", tableID, maxFlowTableID)
+	}
+	return nil
+}
+
+// newFlowTableRow builds the Flow_Table row to insert for a spec
+func newFlowTableRow(spec FlowTableSpec) (map[string]interface{}, error) {
+	switch spec.OverflowPolicy {
+	case "", OverflowPolicyRefuse, OverflowPolicyEvict:
+	default:
+		return nil, fmt.Errorf("The overflow policy %s is invalid, it should be %s or %s", spec.OverflowPolicy, OverflowPolicyRefuse, OverflowPolicyEvict)
+	}
+	if spec.FlowLimit < 0 {
+		return nil, fmt.Errorf("The flow limit %d is invalid", spec.FlowLimit)
+	}
+	if len(spec.Prefixes) > maxFlowPrefixes {
+		return nil, fmt.Errorf("The flow table has %d prefixes, at most %d are allowed", len(spec.Prefixes), maxFlowPrefixes)
+	}
+	table := make(map[string]interface{})
+	if spec.Name != "" {
+		table["name"] = spec.Name
+	}
+	if spec.FlowLimit > 0 {
+		table["flow_limit"] = spec.FlowLimit
+	}
+	if spec.OverflowPolicy != "" {
+		table["overflow_policy"] = spec.OverflowPolicy
+	}
+	if len(spec.Groups) != 0 {
+		table["groups"], _ = libovsdb.NewOvsSet(spec.Groups)
+	}
+	if len(spec.Prefixes) != 0 {
+		table["prefixes"], _ = libovsdb.NewOvsSet(spec.Prefixes)
+	}
+	if len(spec.ExternalIDs) != 0 {
+		table["external_ids"], _ = libovsdb.NewOvsMap(spec.ExternalIDs)
+	}
+	return table, nil
+}
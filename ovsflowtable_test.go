@@ -0,0 +1,56 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestNewFlowTableRow(t *testing.T) {
+	table, err := newFlowTableRow(FlowTableSpec{Name: "classifier", FlowLimit: 1000, OverflowPolicy: OverflowPolicyEvict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table["flow_limit"] != 1000 || table["overflow_policy"] != OverflowPolicyEvict {
+		t.Fatalf("The flow table row %v is incorrect", table)
+	}
+	invalid := []FlowTableSpec{
+		{OverflowPolicy: "drop"},
+		{FlowLimit: -1},
+		{Prefixes: []string{"ip_src", "ip_dst", "ipv6_src", "ipv6_dst"}},
+	}
+	for _, spec := range invalid {
+		if _, err = newFlowTableRow(spec); err == nil {
+			t.Fatalf("The flow table spec %+v should be invalid", spec)
+		}
+	}
+}
+
+func TestGetBridgeFlowTables(t *testing.T) {
+	testClient := &ovsClient{
+		bridgeCache: map[string]*OvsBridge{"br1": {UUID: "br1", Name: "br1"}},
+		flowTableCache: map[string]*OvsFlowTable{
+			"ft1": {UUID: "ft1", Name: "classifier", FlowLimit: 1000},
+		},
+	}
+	tables, _ := libovsdb.NewOvsMap(map[float64]libovsdb.UUID{0: {GoUUID: "ft1"}})
+	bridge := testClient.bridgeCache["br1"]
+	if err := bridge.ReadFromDBRow(&libovsdb.Row{Fields: map[string]interface{}{"flow_tables": *tables}}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := testClient.GetBridgeFlowTables("br1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Name != "classifier" || result[0].FlowLimit != 1000 {
+		t.Fatalf("The flow tables %v are incorrect", result)
+	}
+}
+
+func TestSetBridgeFlowTable(t *testing.T) {
+	// TODO
+}
+
+func TestClearBridgeFlowTable(t *testing.T) {
+	// TODO
+}
@@ -0,0 +1,157 @@
+package goovs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SetBridgeDatapathID pins the OpenFlow datapath ID of a bridge, dpid is 16
+// hex digits optionally preceded by 0x. An empty dpid lets ovs-vswitchd derive
+// the datapath ID from the bridge MAC address again
+func (client *ovsClient) SetBridgeDatapathID(brname, dpid string) error {
+	if dpid != "" {
+		if err := validateDatapathID(dpid); err != nil {
+			return err
+		}
+	}
+	// The check and the update are done under the same lock, so that two
+	// bridges can't pin the same datapath ID concurrently
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	if dpid != "" {
+		if err := client.checkDatapathIDUnique(brname, dpid); err != nil {
+			return err
+		}
+	}
+	return client.updateBridgeConfigLocked(brname, nil, map[string]string{"datapath-id": dpid}, "set bridge datapath id")
+}
+
+// GetBridgeDatapathID returns the datapath ID in use by a bridge, as reported
+// by ovs-vswitchd
+func (client *ovsClient) GetBridgeDatapathID(brname string) (string, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return "", err
+	}
+	return bridge.DatapathID, nil
+}
+
+// SetBridgeHWAddr pins the MAC address of a bridge, an empty address lets
+// ovs-vswitchd pick it from the bridge ports again
+func (client *ovsClient) SetBridgeHWAddr(brname, hwaddr string) error {
+	if hwaddr != "" {
+		if err := validateHWAddr(hwaddr); err != nil {
+			return err
+		}
+	}
+	return client.updateBridgeConfig(brname, nil, map[string]string{"hwaddr": hwaddr}, "set bridge hwaddr")
+}
+
+// GetBridgeDatapathVersion returns the version of the datapath implementation
+// used by a bridge
+func (client *ovsClient) GetBridgeDatapathVersion(brname string) (string, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return "", err
+	}
+	return bridge.DatapathVersion, nil
+}
+
+// CheckDatapathIDs returns an error naming the bridges which share a datapath
+// ID, either pinned in other_config or reported by ovs-vswitchd
+func (client *ovsClient) CheckDatapathIDs() error {
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	owners := make(map[string]string)
+	for _, bridge := range client.bridgeCache {
+		for _, dpid := range bridgeDatapathIDs(bridge) {
+			if owner, ok := owners[dpid]; ok && owner != bridge.Name {
+				return fmt.Errorf("The bridges %s and %s have the same datapath id %s", owner, bridge.Name, dpid)
+			}
+			owners[dpid] = bridge.Name
+		}
+	}
+	return nil
+}
+
+// validateIdentity checks the datapath ID and the MAC address pinned by a
+// bridge spec
+func (client *ovsClient) validateIdentity(spec BridgeSpec) error {
+	if spec.DatapathID != "" {
+		if err := validateDatapathID(spec.DatapathID); err != nil {
+			return err
+		}
+		if err := client.checkDatapathIDUnique(spec.Name, spec.DatapathID); err != nil {
+			return err
+		}
+	}
+	if spec.HWAddr != "" {
+		return validateHWAddr(spec.HWAddr)
+	}
+	return nil
+}
+
+// checkDatapathIDUnique verifies that no other bridge uses the datapath ID
+func (client *ovsClient) checkDatapathIDUnique(brname, dpid string) error {
+	dpid = normalizeDatapathID(dpid)
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	for _, bridge := range client.bridgeCache {
+		if bridge.Name == brname {
+			continue
+		}
+		for _, other := range bridgeDatapathIDs(bridge) {
+			if other == dpid {
+				return fmt.Errorf("The datapath id %s is already used by bridge %s", dpid, bridge.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// bridgeDatapathIDs returns the pinned and the reported datapath ID of a
+// bridge, normalized to 16 lower case hex digits
+func bridgeDatapathIDs(bridge *OvsBridge) []string {
+	var dpids []string
+	if pinned := bridge.OtherConfig["datapath-id"]; pinned != "" {
+		dpids = append(dpids, normalizeDatapathID(pinned))
+	}
+	if bridge.DatapathID != "" {
+		if reported := normalizeDatapathID(bridge.DatapathID); len(dpids) == 0 || dpids[0] != reported {
+			dpids = append(dpids, reported)
+		}
+	}
+	return dpids
+}
+
+func normalizeDatapathID(dpid string) string {
+	return strings.TrimPrefix(strings.ToLower(dpid), "0x")
+}
+
+// validateDatapathID checks the format ovs-vswitchd accepts for
+// other_config:datapath-id, an all zero id is rejected by ovs
+func validateDatapathID(dpid string) error {
+	digits := normalizeDatapathID(dpid)
+	if _, err := hex.DecodeString(digits); err != nil || len(digits) != 16 {
+		return fmt.Errorf("The datapath id %s is invalid, it should be 16 hex digits", dpid)
+	}
+	if digits == strings.Repeat("0", 16) {
+		return fmt.Errorf("The datapath id %s is invalid, it can't be all zero", dpid)
+	}
+	return nil
+}
+
+// validateHWAddr checks that the address is a unicast MAC address, ovs
+// ignores any other value
+func validateHWAddr(hwaddr string) error {
+	mac, err := net.ParseMAC(hwaddr)
+	if err != nil || len(mac) != 6 {
+		return fmt.Errorf("The hwaddr %s is invalid, it should be a MAC address", hwaddr)
+	}
+	if mac[0]&1 != 0 {
+		return fmt.Errorf("The hwaddr %s is invalid, it should be a unicast address", hwaddr)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package goovs
+
+import (
+	"testing"
+)
+
+func TestValidateDatapathID(t *testing.T) {
+	valid := []string{"0000000000000001", "0x00000000000000AB"}
+	for _, dpid := range valid {
+		if err := validateDatapathID(dpid); err != nil {
+			t.Fatalf("The datapath id %s should be valid: %s", dpid, err.Error())
+		}
+	}
+	invalid := []string{"1", "0000000000000000", "000000000000000g", "00000000000000001"}
+	for _, dpid := range invalid {
+		if err := validateDatapathID(dpid); err == nil {
+			t.Fatalf("The datapath id %s should be invalid", dpid)
+		}
+	}
+}
+
+func TestValidateHWAddr(t *testing.T) {
+	if err := validateHWAddr("02:00:00:00:00:01"); err != nil {
+		t.Fatal(err)
+	}
+	for _, hwaddr := range []string{"01:00:5e:00:00:01", "02:00:00:00:00", "bridge"} {
+		if err := validateHWAddr(hwaddr); err == nil {
+			t.Fatalf("The hwaddr %s should be invalid", hwaddr)
+		}
+	}
+}
+
+func TestCheckDatapathIDs(t *testing.T) {
+	testClient := &ovsClient{bridgeCache: map[string]*OvsBridge{
+		"br1": {UUID: "br1", Name: "br1", DatapathID: "00000000000000ab"},
+		"br2": {UUID: "br2", Name: "br2", OtherConfig: map[string]string{"datapath-id": "0x00000000000000AC"}},
+	}}
+	if err := testClient.CheckDatapathIDs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := testClient.checkDatapathIDUnique("br3", "00000000000000AC"); err == nil {
+		t.Fatal("The datapath id of br2 should not be allowed on br3")
+	}
+	testClient.bridgeCache["br2"].DatapathID = "00000000000000ab"
+	if err := testClient.CheckDatapathIDs(); err == nil {
+		t.Fatal("The duplicate datapath id should be reported")
+	}
+}
+
+func TestSetBridgeDatapathID(t *testing.T) {
+	// TODO
+}
+
+func TestSetBridgeHWAddr(t *testing.T) {
+	// TODO
+}
+
+func TestGetBridgeDatapath(t *testing.T) {
+	// TODO
+}
@@ -0,0 +1,110 @@
+package goovs
+
+import (
+	"sync"
+	"time"
+)
+
+// Instrumentation receives measurements about the client activity, see the
+// metrics package for a Prometheus implementation
+type Instrumentation interface {
+	// TransactionDone is called once a transaction completes, action is the
+	// label of the transaction, e.g. "create bridge" or "delete port"
+	TransactionDone(action string, duration time.Duration, err error)
+	// MonitorRowsUpdated is called with the number of rows of a table carried
+	// by a monitor update
+	MonitorRowsUpdated(table string, rows int)
+	// CacheSizeChanged is called with the number of objects in a cache after
+	// it is updated
+	CacheSizeChanged(cache string, size int)
+	// Disconnected is called when a session towards ovsdb-server is lost
+	Disconnected(session string)
+	// Reconnected is called when a lost session is established again
+	Reconnected(session string)
+	// Warning is called when a request succeeds but is likely not what the
+	// caller expects, kind classifies the warning, e.g. "interface_type"
+	Warning(kind, message string)
+}
+
+// Tracer starts a span around each RPC sent to ovsdb-server
+type Tracer interface {
+	StartSpan(method, action string) Span
+}
+
+// Span is ended once the RPC completes, err is the RPC error if any
+type Span interface {
+	End(err error)
+}
+
+const (
+	ovsdbSession    = "ovsdb"
+	lockSessionName = "lock"
+)
+
+const interfaceTypeWarningKind = "interface_type"
+
+var instrumentation Instrumentation = nopInstrumentation{}
+var tracer Tracer = nopTracer{}
+var instrumentationLock sync.RWMutex
+
+// SetInstrumentation installs the instrumentation used by the client, nil
+// turns the instrumentation off
+func SetInstrumentation(instr Instrumentation) {
+	instrumentationLock.Lock()
+	defer instrumentationLock.Unlock()
+	if instr == nil {
+		instr = nopInstrumentation{}
+	}
+	instrumentation = instr
+}
+
+// SetTracer installs the tracer used by the client, nil turns tracing off
+func SetTracer(t Tracer) {
+	instrumentationLock.Lock()
+	defer instrumentationLock.Unlock()
+	if t == nil {
+		t = nopTracer{}
+	}
+	tracer = t
+}
+
+func getInstrumentation() Instrumentation {
+	instrumentationLock.RLock()
+	defer instrumentationLock.RUnlock()
+	return instrumentation
+}
+
+func startSpan(method, action string) Span {
+	instrumentationLock.RLock()
+	defer instrumentationLock.RUnlock()
+	return tracer.StartSpan(method, action)
+}
+
+type nopInstrumentation struct {
+}
+
+func (nopInstrumentation) TransactionDone(string, time.Duration, error) {
+}
+func (nopInstrumentation) MonitorRowsUpdated(string, int) {
+}
+func (nopInstrumentation) CacheSizeChanged(string, int) {
+}
+func (nopInstrumentation) Disconnected(string) {
+}
+func (nopInstrumentation) Reconnected(string) {
+}
+func (nopInstrumentation) Warning(string, string) {
+}
+
+type nopTracer struct {
+}
+
+func (nopTracer) StartSpan(string, string) Span {
+	return nopSpan{}
+}
+
+type nopSpan struct {
+}
+
+func (nopSpan) End(error) {
+}
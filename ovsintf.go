@@ -9,10 +9,13 @@ import (
 
 // OvsInterface is the structure represents an interface row
 type OvsInterface struct {
-	UUID    string            `json:"_uuid"`
-	Name    string            `json:"name"`
-	Options map[string]string `json:"options"`
-	Type    string            `json:"type"`
+	UUID        string            `json:"_uuid"`
+	Name        string            `json:"name"`
+	Options     map[string]string `json:"options"`
+	Type        string            `json:"type"`
+	LLDPEnable  bool              `json:"lldp"`
+	MACInUse    string            `json:"mac_in_use"`
+	LACPCurrent *bool             `json:"lacp_current"`
 }
 
 // ReadFromDBRow is used to initialize the object from a row
@@ -26,6 +29,14 @@ func (intf *OvsInterface) ReadFromDBRow(row *libovsdb.Row) error {
 			intf.Name = value.(string)
 		case "type":
 			intf.Type = value.(string)
+		case "mac_in_use":
+			intf.MACInUse, _ = stringFromValue(value)
+		case "lacp_current":
+			if current, ok := boolFromValue(value); ok {
+				intf.LACPCurrent = &current
+			}
+		case "lldp":
+			intf.LLDPEnable = mapFromValue(value)["enable"] == "true"
 		case "options":
 			for key, opt := range value.(libovsdb.OvsMap).GoMap {
 				intf.Options[key.(string)] = opt.(string)
@@ -60,8 +71,10 @@ func (client *ovsClient) AddPeerInterfaceOnPort(portname, peername string) error
 	// intf row to insert
 	intf := make(map[string]interface{})
 	intf["name"] = portname
-	intf["type"] = `peer`
-	intf["options"] = fmt.Sprintf("{peer=%s}", peername)
+	intf["type"] = `patch`
+	options := make(map[string]interface{})
+	options["peer"] = peername
+	intf["options"], _ = libovsdb.NewOvsMap(options)
 
 	return client.addInterfaceOnPort(portname, intf)
 }
@@ -97,7 +110,6 @@ func (client *ovsClient) addInterfaceOnPort(portName string, intf map[string]int
 func (client *ovsClient) RemoveInterfaceFromPort(portname, interfaceUUID string) error {
 	intfUpdateLock.Lock()
 	defer intfUpdateLock.Unlock()
-	namedInterfaceUUID := "gointerface"
 	interfaceDeleteCondition := libovsdb.NewCondition("_uuid", "==", []string{"uuid", interfaceUUID})
 	interfaceDeleteOp := libovsdb.Operation{
 		Op:    deleteOperation,
@@ -106,7 +118,7 @@ func (client *ovsClient) RemoveInterfaceFromPort(portname, interfaceUUID string)
 	}
 
 	// Inserting a Port row in Port table requires mutating the Bridge table
-	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: namedInterfaceUUID}}
+	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: interfaceUUID}}
 	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
 	mutation := libovsdb.NewMutation("interfaces", deleteOperation, mutateSet)
 	condition := libovsdb.NewCondition("name", "==", portname)
@@ -123,6 +135,18 @@ func (client *ovsClient) RemoveInterfaceFromPort(portname, interfaceUUID string)
 	return client.transact(operations, "remove interface")
 }
 
+func (client *ovsClient) getInterfaceByName(intfname string) (*OvsInterface, error) {
+	intfCacheUpdateLock.RLock()
+	defer intfCacheUpdateLock.RUnlock()
+	for _, intf := range client.interfaceCache {
+		if intf.Name == intfname {
+			intfObj := *intf
+			return &intfObj, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find the interface with name %s", intfname)
+}
+
 func (client *ovsClient) interfaceUUIDExists(interfaceUUID string) (bool, error) {
 	if interfaceUUID == "" {
 		return false, fmt.Errorf("The interface uuid is not valid")
@@ -142,12 +166,9 @@ func (client *ovsClient) findAllInterfaceUUIDOnPort(portname string) ([]string,
 		Columns: []string{"interfaces"},
 	}
 	operations := []libovsdb.Operation{selectOp}
-	reply, _ := client.dbClient.Transact(defaultOvsDB, operations...)
-	if len(reply) < len(operations) {
-		return nil, fmt.Errorf("Get interface from port failed due to Number of Replies should be at least equal to number of Operations")
-	}
-	if reply[0].Error != "" {
-		return nil, fmt.Errorf("Get interface from port failed due to Transaction Failed due to an error: %v", reply[0].Error)
+	reply, _, err := client.transactWithReply(operations, "get interface from port")
+	if err != nil {
+		return nil, err
 	}
 	if len(reply[0].Rows) == 0 {
 		return nil, nil
@@ -0,0 +1,359 @@
+package goovs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+// LockHandler is notified about the ovsdb locks requested by the client
+type LockHandler interface {
+	// LockAcquired is called when a lock requested by Lock is granted
+	LockAcquired(lockID string)
+	// LockStolen is called when another client steals the lock, or when the
+	// connection holding the lock is lost
+	LockStolen(lockID string)
+}
+
+// ovsdb locks belong to the session which takes them, and an assert only
+// passes within that session. libovsdb neither exposes the lock methods nor
+// allows an assert operation, so the locks are taken on a dedicated session
+// which also carries the transactions asserting the lock.
+type lockSession struct {
+	conn     net.Conn
+	encoder  *json.Encoder
+	mutex    sync.Mutex
+	nextID   uint64
+	pending  map[uint64]chan rpcResult
+	closed   bool
+	onNotify func(method, lockID string)
+	onClose  func()
+}
+
+type rpcResult struct {
+	result json.RawMessage
+	err    error
+}
+
+type rpcMessage struct {
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  interface{}     `json:"error,omitempty"`
+	ID     json.RawMessage `json:"id,omitempty"`
+}
+
+// lockEvent is a lock change to notify to the LockHandlers
+type lockEvent struct {
+	lockID   string
+	locked   bool
+	handlers []LockHandler
+}
+
+var lockUpdateLock sync.Mutex
+
+func newLockSession(network, address string, onNotify func(method, lockID string), onClose func()) (*lockSession, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	session := &lockSession{
+		conn:     conn,
+		encoder:  json.NewEncoder(conn),
+		pending:  make(map[uint64]chan rpcResult),
+		onNotify: onNotify,
+		onClose:  onClose,
+	}
+	go session.serve()
+	return session, nil
+}
+
+func (session *lockSession) serve() {
+	decoder := json.NewDecoder(session.conn)
+	var err error
+	for {
+		var msg rpcMessage
+		if err = decoder.Decode(&msg); err != nil {
+			break
+		}
+		if msg.Method != "" {
+			session.handleRequest(&msg)
+			continue
+		}
+		var id uint64
+		if json.Unmarshal(msg.ID, &id) != nil {
+			continue
+		}
+		session.mutex.Lock()
+		resultChan, ok := session.pending[id]
+		delete(session.pending, id)
+		session.mutex.Unlock()
+		if !ok {
+			continue
+		}
+		if msg.Error != nil {
+			resultChan <- rpcResult{err: fmt.Errorf("%v", msg.Error)}
+		} else {
+			resultChan <- rpcResult{result: msg.Result}
+		}
+	}
+	session.close(fmt.Errorf("The lock session is closed due to %s", err.Error()))
+}
+
+func (session *lockSession) handleRequest(msg *rpcMessage) {
+	switch msg.Method {
+	case "echo":
+		session.mutex.Lock()
+		session.encoder.Encode(map[string]interface{}{"result": msg.Params, "error": nil, "id": msg.ID})
+		session.mutex.Unlock()
+	case "locked", "stolen":
+		var params []string
+		if json.Unmarshal(msg.Params, &params) == nil && len(params) == 1 {
+			session.onNotify(msg.Method, params[0])
+		}
+	}
+}
+
+// call sends a request and waits for its result
+func (session *lockSession) call(method string, params []interface{}, result interface{}) error {
+	resultChan := make(chan rpcResult, 1)
+	session.mutex.Lock()
+	if session.closed {
+		session.mutex.Unlock()
+		return fmt.Errorf("The lock session is closed")
+	}
+	id := session.nextID
+	session.nextID++
+	session.pending[id] = resultChan
+	span := startSpan(method, lockSessionName)
+	err := session.encoder.Encode(map[string]interface{}{"method": method, "params": params, "id": id})
+	if err != nil {
+		delete(session.pending, id)
+	}
+	session.mutex.Unlock()
+	if err != nil {
+		span.End(err)
+		return err
+	}
+	reply := <-resultChan
+	span.End(reply.err)
+	if reply.err != nil {
+		return reply.err
+	}
+	if result == nil || len(reply.result) == 0 || bytes.Equal(reply.result, []byte("null")) {
+		return nil
+	}
+	return json.Unmarshal(reply.result, result)
+}
+
+func (session *lockSession) close(err error) {
+	session.mutex.Lock()
+	if session.closed {
+		session.mutex.Unlock()
+		return
+	}
+	session.closed = true
+	session.conn.Close()
+	for id, resultChan := range session.pending {
+		resultChan <- rpcResult{err: err}
+		delete(session.pending, id)
+	}
+	session.mutex.Unlock()
+	session.onClose()
+}
+
+// RegisterLockHandler registers a handler notified about lock changes. The
+// handlers are called in order on a goroutine of their own, so they may call
+// Lock, Steal, Unlock or transact while the lock is asserted
+func (client *ovsClient) RegisterLockHandler(handler LockHandler) {
+	lockUpdateLock.Lock()
+	defer lockUpdateLock.Unlock()
+	client.lockHandlers = append(client.lockHandlers, handler)
+}
+
+// Lock requests the ovsdb lock with the given id. It returns true if the lock
+// is acquired immediately, otherwise the request is queued by ovsdb-server
+// and the LockHandlers are notified once the lock is granted
+func (client *ovsClient) Lock(lockID string) (bool, error) {
+	return client.requestLock("lock", lockID)
+}
+
+// Steal takes the ovsdb lock with the given id away from its current owner
+func (client *ovsClient) Steal(lockID string) error {
+	_, err := client.requestLock("steal", lockID)
+	return err
+}
+
+// Unlock releases the ovsdb lock with the given id, or cancels the pending
+// request for it
+func (client *ovsClient) Unlock(lockID string) error {
+	if lockID == "" {
+		return fmt.Errorf("The lock id is invalid")
+	}
+	session, err := client.getLockSession()
+	if err != nil {
+		return err
+	}
+	if err = session.call("unlock", []interface{}{lockID}, nil); err != nil {
+		return fmt.Errorf("Failed to unlock %s due to %s", lockID, err.Error())
+	}
+	lockUpdateLock.Lock()
+	delete(client.heldLocks, lockID)
+	lockUpdateLock.Unlock()
+	return nil
+}
+
+// HasLock tells whether the client currently holds the lock
+func (client *ovsClient) HasLock(lockID string) bool {
+	lockUpdateLock.Lock()
+	defer lockUpdateLock.Unlock()
+	return client.heldLocks[lockID]
+}
+
+// AssertLock makes every following transaction assert that the client holds
+// the lock, so that ovsdb-server rejects it otherwise. An empty id turns the
+// assertion off
+func (client *ovsClient) AssertLock(lockID string) {
+	lockUpdateLock.Lock()
+	defer lockUpdateLock.Unlock()
+	client.assertLockID = lockID
+}
+
+func (client *ovsClient) requestLock(method, lockID string) (bool, error) {
+	if lockID == "" {
+		return false, fmt.Errorf("The lock id is invalid")
+	}
+	session, err := client.getLockSession()
+	if err != nil {
+		return false, err
+	}
+	var reply struct {
+		Locked bool `json:"locked"`
+	}
+	if err = session.call(method, []interface{}{lockID}, &reply); err != nil {
+		return false, fmt.Errorf("Failed to %s %s due to %s", method, lockID, err.Error())
+	}
+	if reply.Locked {
+		client.lockChanged(lockID, true)
+	}
+	return reply.Locked, nil
+}
+
+// getLockSession returns the session used for locks, opening it if needed
+func (client *ovsClient) getLockSession() (*lockSession, error) {
+	lockUpdateLock.Lock()
+	defer lockUpdateLock.Unlock()
+	if client.lockSession != nil {
+		return client.lockSession, nil
+	}
+	session, err := newLockSession(client.network, client.address, client.lockNotification, client.lockSessionClosed)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the lock session due to %s", err.Error())
+	}
+	client.lockSession = session
+	if client.lockSessionLost {
+		client.lockSessionLost = false
+		getInstrumentation().Reconnected(lockSessionName)
+	}
+	return session, nil
+}
+
+func (client *ovsClient) lockNotification(method, lockID string) {
+	client.lockChanged(lockID, method == "locked")
+}
+
+func (client *ovsClient) lockSessionClosed() {
+	getInstrumentation().Disconnected(lockSessionName)
+	lockUpdateLock.Lock()
+	client.lockSession = nil
+	client.lockSessionLost = true
+	var lost []string
+	for lockID := range client.heldLocks {
+		lost = append(lost, lockID)
+	}
+	lockUpdateLock.Unlock()
+	for _, lockID := range lost {
+		client.lockChanged(lockID, false)
+	}
+}
+
+func (client *ovsClient) lockChanged(lockID string, locked bool) {
+	lockUpdateLock.Lock()
+	if client.heldLocks[lockID] == locked {
+		lockUpdateLock.Unlock()
+		return
+	}
+	if locked {
+		client.heldLocks[lockID] = true
+	} else {
+		delete(client.heldLocks, lockID)
+	}
+	handlers := make([]LockHandler, len(client.lockHandlers))
+	copy(handlers, client.lockHandlers)
+	client.lockEvents = append(client.lockEvents, lockEvent{lockID: lockID, locked: locked, handlers: handlers})
+	if !client.lockNotifying {
+		client.lockNotifying = true
+		go client.notifyLockHandlers()
+	}
+	lockUpdateLock.Unlock()
+}
+
+// notifyLockHandlers calls the handlers for the queued lock changes. It runs
+// apart from the lock session reader, which the handlers may wait for
+func (client *ovsClient) notifyLockHandlers() {
+	for {
+		lockUpdateLock.Lock()
+		if len(client.lockEvents) == 0 {
+			client.lockNotifying = false
+			lockUpdateLock.Unlock()
+			return
+		}
+		event := client.lockEvents[0]
+		client.lockEvents = client.lockEvents[1:]
+		lockUpdateLock.Unlock()
+		for _, handler := range event.handlers {
+			if event.locked {
+				handler.LockAcquired(event.lockID)
+			} else {
+				handler.LockStolen(event.lockID)
+			}
+		}
+	}
+}
+
+// transactAssertingLock sends the operations on the lock session preceded
+// by an assert on the lock. The replies are returned without the assert
+func (client *ovsClient) transactAssertingLock(lockID string, operations []libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+	session, err := client.getLockSession()
+	if err != nil {
+		return nil, err
+	}
+	params := []interface{}{defaultOvsDB, map[string]string{"op": "assert", "lock": lockID}}
+	for _, operation := range operations {
+		params = append(params, operation)
+	}
+	var reply []libovsdb.OperationResult
+	if err = session.call("transact", params, &reply); err != nil {
+		return nil, err
+	}
+	if len(reply) == 0 {
+		return nil, fmt.Errorf("No reply is received for the lock assertion")
+	}
+	if reply[0].Error != "" {
+		return nil, fmt.Errorf("The lock %s is not held: %s", lockID, reply[0].Error)
+	}
+	return reply[1:], nil
+}
+
+func (client *ovsClient) closeLockSession() {
+	lockUpdateLock.Lock()
+	session := client.lockSession
+	lockUpdateLock.Unlock()
+	if session != nil {
+		session.close(fmt.Errorf("The client is disconnected"))
+	}
+}
@@ -0,0 +1,248 @@
+package goovs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testLockHandler struct {
+	acquired chan string
+	stolen   chan string
+}
+
+func (handler *testLockHandler) LockAcquired(lockID string) {
+	handler.acquired <- lockID
+}
+
+func (handler *testLockHandler) LockStolen(lockID string) {
+	handler.stolen <- lockID
+}
+
+// serveFakeLocks answers lock requests with locked=false, then grants and
+// steals the lock through notifications like ovsdb-server does. Steal
+// requests are granted immediately
+func serveFakeLocks(t *testing.T, listener net.Listener) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var request struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+			ID     interface{}   `json:"id"`
+		}
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+		switch request.Method {
+		case "lock":
+			encoder.Encode(map[string]interface{}{"result": map[string]bool{"locked": false}, "error": nil, "id": request.ID})
+			encoder.Encode(map[string]interface{}{"method": "locked", "params": request.Params, "id": nil})
+			encoder.Encode(map[string]interface{}{"method": "stolen", "params": request.Params, "id": nil})
+		case "steal":
+			encoder.Encode(map[string]interface{}{"result": map[string]bool{"locked": true}, "error": nil, "id": request.ID})
+		case "unlock":
+			encoder.Encode(map[string]interface{}{"result": map[string]interface{}{}, "error": nil, "id": request.ID})
+		}
+	}
+}
+
+// serveFakeTransactions answers the transactions asserting a lock with
+// empty results, and sends their operations to the channel
+func serveFakeTransactions(listener net.Listener, transactions chan<- []map[string]interface{}) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     interface{}       `json:"id"`
+		}
+		if err := decoder.Decode(&request); err != nil || len(request.Params) < 2 {
+			return
+		}
+		// the params are the database name, the assert and the operations
+		results := make([]map[string]interface{}, len(request.Params)-1)
+		encoder.Encode(map[string]interface{}{"result": results, "error": nil, "id": request.ID})
+		operations := make([]map[string]interface{}, 0, len(request.Params)-2)
+		for _, param := range request.Params[2:] {
+			var operation map[string]interface{}
+			json.Unmarshal(param, &operation)
+			operations = append(operations, operation)
+		}
+		transactions <- operations
+	}
+}
+
+// newFakeTransactClient makes the client send its transactions to
+// serveFakeTransactions through the lock session, the returned function
+// releases it
+func newFakeTransactClient(t *testing.T, testClient *ovsClient) (<-chan []map[string]interface{}, func()) {
+	dir, err := ioutil.TempDir("", "goovs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "db.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	transactions := make(chan []map[string]interface{}, 4)
+	go serveFakeTransactions(listener, transactions)
+	testClient.network = "unix"
+	testClient.address = socket
+	testClient.heldLocks = make(map[string]bool)
+	testClient.assertLockID = "goovs"
+	return transactions, func() {
+		testClient.closeLockSession()
+		listener.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// newFakeLockClient returns a client whose lock session is served by
+// serveFakeLocks, the returned function releases it
+func newFakeLockClient(t *testing.T) (*ovsClient, func()) {
+	dir, err := ioutil.TempDir("", "goovs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "db.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	go serveFakeLocks(t, listener)
+	lockClient := &ovsClient{network: "unix", address: socket, heldLocks: make(map[string]bool)}
+	return lockClient, func() {
+		lockClient.closeLockSession()
+		listener.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLock(t *testing.T) {
+	lockClient, release := newFakeLockClient(t)
+	defer release()
+	handler := &testLockHandler{acquired: make(chan string, 1), stolen: make(chan string, 1)}
+	lockClient.RegisterLockHandler(handler)
+
+	locked, err := lockClient.Lock("agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked {
+		t.Fatal("The lock should not be acquired immediately")
+	}
+	select {
+	case lockID := <-handler.acquired:
+		if lockID != "agent" {
+			t.Fatalf("The acquired lock %s is incorrect", lockID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The lock is never acquired")
+	}
+	select {
+	case <-handler.stolen:
+	case <-time.After(time.Second):
+		t.Fatal("The lock is never stolen")
+	}
+	if lockClient.HasLock("agent") {
+		t.Fatal("The lock should be lost after it is stolen")
+	}
+	if err = lockClient.Unlock("agent"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unlockingHandler releases the lock as soon as it is acquired
+type unlockingHandler struct {
+	client   *ovsClient
+	unlocked chan error
+}
+
+func (handler *unlockingHandler) LockAcquired(lockID string) {
+	handler.unlocked <- handler.client.Unlock(lockID)
+}
+
+func (handler *unlockingHandler) LockStolen(lockID string) {
+}
+
+func TestUnlockFromLockHandler(t *testing.T) {
+	lockClient, release := newFakeLockClient(t)
+	defer release()
+	handler := &unlockingHandler{client: lockClient, unlocked: make(chan error, 1)}
+	lockClient.RegisterLockHandler(handler)
+
+	if _, err := lockClient.Lock("agent"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-handler.unlocked:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The handler calling Unlock is deadlocked")
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	lockClient, release := newFakeLockClient(t)
+	defer release()
+	if err := lockClient.Unlock(""); err == nil {
+		t.Fatal("The empty lock id should be rejected")
+	}
+	if err := lockClient.Steal("agent"); err != nil {
+		t.Fatal(err)
+	}
+	if !lockClient.HasLock("agent") {
+		t.Fatal("The lock should be held")
+	}
+	if err := lockClient.Unlock("agent"); err != nil {
+		t.Fatal(err)
+	}
+	if lockClient.HasLock("agent") {
+		t.Fatal("The lock should be released")
+	}
+}
+
+func TestSteal(t *testing.T) {
+	lockClient, release := newFakeLockClient(t)
+	defer release()
+	handler := &testLockHandler{acquired: make(chan string, 1), stolen: make(chan string, 1)}
+	lockClient.RegisterLockHandler(handler)
+	if err := lockClient.Steal(""); err == nil {
+		t.Fatal("The empty lock id should be rejected")
+	}
+	if err := lockClient.Steal("agent"); err != nil {
+		t.Fatal(err)
+	}
+	if !lockClient.HasLock("agent") {
+		t.Fatal("The stolen lock should be held")
+	}
+	select {
+	case lockID := <-handler.acquired:
+		if lockID != "agent" {
+			t.Fatalf("The acquired lock %s is incorrect", lockID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The handler is never notified about the stolen lock")
+	}
+}
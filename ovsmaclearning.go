@@ -0,0 +1,62 @@
+package goovs
+
+import (
+	"math"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+// MACLearningSpec holds the MAC learning settings of a bridge, the aging
+// time is in seconds. SetBridgeMACLearning clears a zero setting
+type MACLearningSpec struct {
+	AgingTime int
+	TableSize int
+}
+
+// SetBridgeFloodVLANs sets the VLANs on which MAC learning is disabled, the
+// traffic of these VLANs is flooded. An empty list enables learning on all
+// VLANs again
+func (client *ovsClient) SetBridgeFloodVLANs(brname string, vlans []int) error {
+	for _, vlan := range vlans {
+		if err := validateVlanTag(vlan); err != nil {
+			return err
+		}
+	}
+	if vlans == nil {
+		vlans = []int{}
+	}
+	vlanSet, _ := libovsdb.NewOvsSet(vlans)
+	return client.updateBridgeColumn(brname, "flood_vlans", vlanSet, "set bridge flood vlans")
+}
+
+// GetBridgeFloodVLANs returns the VLANs on which MAC learning is disabled
+func (client *ovsClient) GetBridgeFloodVLANs(brname string) ([]int, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return nil, err
+	}
+	return bridge.FloodVLANs, nil
+}
+
+// SetBridgeMACLearning sets the aging time and the size of the MAC learning
+// table of a bridge
+func (client *ovsClient) SetBridgeMACLearning(brname string, spec MACLearningSpec) error {
+	config, err := newIntConfig([]intSetting{
+		{"mac-aging-time", nonZeroInt(spec.AgingTime), 1, math.MaxInt32},
+		{"mac-table-size", nonZeroInt(spec.TableSize), 1, math.MaxInt32},
+	})
+	if err != nil {
+		return err
+	}
+	return client.updateBridgeConfig(brname, nil, config, "set bridge mac learning")
+}
+
+// GetBridgeMACLearning returns the MAC learning settings of a bridge, zero
+// values tell the ovs defaults are used
+func (client *ovsClient) GetBridgeMACLearning(brname string) (MACLearningSpec, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return MACLearningSpec{}, err
+	}
+	return MACLearningSpec{AgingTime: bridge.MACAgingTime, TableSize: bridge.MACTableSize}, nil
+}
@@ -0,0 +1,34 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestReadBridgeMACLearning(t *testing.T) {
+	floodVLANs, _ := libovsdb.NewOvsSet([]float64{10, 20})
+	otherConfig, _ := libovsdb.NewOvsMap(map[string]string{"mac-aging-time": "60", "mac-table-size": "2048"})
+	bridge := &OvsBridge{}
+	err := bridge.ReadFromDBRow(&libovsdb.Row{Fields: map[string]interface{}{
+		"flood_vlans":  *floodVLANs,
+		"other_config": *otherConfig,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bridge.FloodVLANs) != 2 || bridge.MACAgingTime != 60 || bridge.MACTableSize != 2048 {
+		t.Fatalf("The mac learning settings of %+v are incorrect", bridge)
+	}
+}
+
+func TestSetBridgeFloodVLANs(t *testing.T) {
+	testClient := &ovsClient{bridgeCache: map[string]*OvsBridge{}}
+	if err := testClient.SetBridgeFloodVLANs("br1", []int{10, 4096}); err == nil {
+		t.Fatal("The vlan 4096 should be invalid")
+	}
+}
+
+func TestSetBridgeMACLearning(t *testing.T) {
+	// TODO
+}
@@ -0,0 +1,71 @@
+package goovs
+
+import (
+	"math"
+	"strconv"
+)
+
+// McastSnoopingSpec holds the bridge wide multicast snooping settings, the
+// aging time is in seconds. A zero size or time clears the setting
+type McastSnoopingSpec struct {
+	TableSize                int
+	AgingTime                int
+	DisableFloodUnregistered bool
+}
+
+// PortMcastSnoopingSpec holds the multicast snooping settings of a port
+type PortMcastSnoopingSpec struct {
+	// Flood forwards all the multicast traffic to the port
+	Flood bool
+	// FloodReports forwards the IGMP and MLD reports to the port
+	FloodReports bool
+}
+
+// SetBridgeMcastSnooping enables or disables IGMP and MLD snooping on a bridge
+func (client *ovsClient) SetBridgeMcastSnooping(brname string, enable bool, spec McastSnoopingSpec) error {
+	config, err := newIntConfig([]intSetting{
+		{"mcast-snooping-table-size", nonZeroInt(spec.TableSize), 1, math.MaxInt32},
+		{"mcast-snooping-aging-time", nonZeroInt(spec.AgingTime), 15, 3600},
+	})
+	if err != nil {
+		return err
+	}
+	config["mcast-snooping-disable-flood-unregistered"] = strconv.FormatBool(spec.DisableFloodUnregistered)
+	bridge := make(map[string]interface{})
+	bridge["mcast_snooping_enable"] = enable
+	return client.updateBridgeConfig(brname, bridge, config, "set bridge mcast snooping")
+}
+
+// GetBridgeMcastSnooping returns whether multicast snooping is enabled on a
+// bridge and its settings
+func (client *ovsClient) GetBridgeMcastSnooping(brname string) (bool, McastSnoopingSpec, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return false, McastSnoopingSpec{}, err
+	}
+	spec := McastSnoopingSpec{}
+	spec.TableSize, _ = strconv.Atoi(bridge.OtherConfig["mcast-snooping-table-size"])
+	spec.AgingTime, _ = strconv.Atoi(bridge.OtherConfig["mcast-snooping-aging-time"])
+	spec.DisableFloodUnregistered = bridge.OtherConfig["mcast-snooping-disable-flood-unregistered"] == "true"
+	return bridge.McastSnoopingEnable, spec, nil
+}
+
+// SetPortMcastSnooping sets the multicast snooping flooding of a port
+func (client *ovsClient) SetPortMcastSnooping(portname string, spec PortMcastSnoopingSpec) error {
+	config := make(map[string]string)
+	config["mcast-snooping-flood"] = strconv.FormatBool(spec.Flood)
+	config["mcast-snooping-flood-reports"] = strconv.FormatBool(spec.FloodReports)
+	return client.updatePortOtherConfig(portname, config, "set port mcast snooping")
+}
+
+// GetPortMcastSnooping returns the multicast snooping settings of a port
+func (client *ovsClient) GetPortMcastSnooping(portname string) (PortMcastSnoopingSpec, error) {
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return PortMcastSnoopingSpec{}, err
+	}
+	return PortMcastSnoopingSpec{
+		Flood:        port.OtherConfig["mcast-snooping-flood"] == "true",
+		FloodReports: port.OtherConfig["mcast-snooping-flood-reports"] == "true",
+	}, nil
+}
@@ -0,0 +1,42 @@
+package goovs
+
+import (
+	"testing"
+)
+
+func TestGetBridgeMcastSnooping(t *testing.T) {
+	testClient := &ovsClient{bridgeCache: map[string]*OvsBridge{
+		"br1": {UUID: "br1", Name: "br1", McastSnoopingEnable: true, OtherConfig: map[string]string{
+			"mcast-snooping-table-size":                 "4096",
+			"mcast-snooping-disable-flood-unregistered": "true",
+		}},
+	}}
+	enabled, spec, err := testClient.GetBridgeMcastSnooping("br1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enabled || spec.TableSize != 4096 || spec.AgingTime != 0 || !spec.DisableFloodUnregistered {
+		t.Fatalf("The mcast snooping settings %+v are incorrect", spec)
+	}
+}
+
+func TestGetPortMcastSnooping(t *testing.T) {
+	testClient := &ovsClient{portCache: map[string]*OvsPort{
+		"p1": {UUID: "p1", Name: "p1", OtherConfig: map[string]string{"mcast-snooping-flood-reports": "true"}},
+	}}
+	spec, err := testClient.GetPortMcastSnooping("p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Flood || !spec.FloodReports {
+		t.Fatalf("The port mcast snooping settings %+v are incorrect", spec)
+	}
+}
+
+func TestSetBridgeMcastSnooping(t *testing.T) {
+	// TODO
+}
+
+func TestSetPortMcastSnooping(t *testing.T) {
+	// TODO
+}
@@ -0,0 +1,257 @@
+package goovs
+
+import (
+	"fmt"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const mirrorTableName = "Mirror"
+
+// MirrorSpec describes a mirror of a bridge. The selected traffic is sent to
+// either OutputPort (SPAN) or OutputVLAN (RSPAN), the ports are given by name
+type MirrorSpec struct {
+	Name           string
+	SelectSrcPorts []string
+	SelectDstPorts []string
+	SelectVLANs    []int
+	SelectAll      bool
+	OutputPort     string
+	OutputVLAN     int
+}
+
+// OvsMirror is the structure represents a mirror row, the ports are resolved
+// to their names
+type OvsMirror struct {
+	UUID           string           `json:"_uuid"`
+	Name           string           `json:"name"`
+	SelectSrcPorts []string         `json:"select_src_port"`
+	SelectDstPorts []string         `json:"select_dst_port"`
+	SelectVLANs    []int            `json:"select_vlan"`
+	SelectAll      bool             `json:"select_all"`
+	OutputPort     string           `json:"output_port"`
+	OutputVLAN     int              `json:"output_vlan"`
+	Statistics     MirrorStatistics `json:"statistics"`
+}
+
+// MirrorStatistics holds the counters of the mirrored traffic
+type MirrorStatistics struct {
+	TxPackets int `json:"tx_packets"`
+	TxBytes   int `json:"tx_bytes"`
+}
+
+// CreateMirror adds a mirror to a bridge, the mirror name must be unique
+// within the bridge
+func (client *ovsClient) CreateMirror(brname string, spec MirrorSpec) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return err
+	}
+	if spec.Name == "" {
+		return fmt.Errorf("The mirror name is invalid")
+	}
+	if _, err = client.getMirrorUUID(bridge, spec.Name); err == nil {
+		return fmt.Errorf("The mirror %s already exists on bridge %s", spec.Name, brname)
+	}
+	mirror, err := client.newMirrorRow(bridge, spec)
+	if err != nil {
+		return err
+	}
+
+	namedMirrorUUID := "gomirror"
+	insertMirrorOp := libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    mirrorTableName,
+		Row:      mirror,
+		UUIDName: namedMirrorUUID,
+	}
+
+	// Inserting a Mirror row requires mutating the mirrors of the bridge
+	mutateSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUUID: namedMirrorUUID}})
+	mutation := libovsdb.NewMutation("mirrors", insertOperation, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", brname)
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     bridgeTableName,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+
+	operations := []libovsdb.Operation{insertMirrorOp, mutateOp}
+	return client.transact(operations, "create mirror")
+}
+
+// DeleteMirror removes a mirror from a bridge, ovsdb-server deletes the
+// mirror row once the bridge no longer refers to it
+func (client *ovsClient) DeleteMirror(brname, mirrorname string) error {
+	bridgeUpdateLock.Lock()
+	defer bridgeUpdateLock.Unlock()
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return err
+	}
+	mirrorUUID, err := client.getMirrorUUID(bridge, mirrorname)
+	if err != nil {
+		return nil
+	}
+	mutateSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUUID: mirrorUUID}})
+	mutation := libovsdb.NewMutation("mirrors", deleteOperation, mutateSet)
+	condition := libovsdb.NewCondition("name", "==", brname)
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     bridgeTableName,
+		Mutations: []interface{}{mutation},
+		Where:     []interface{}{condition},
+	}
+	return client.transact([]libovsdb.Operation{mutateOp}, "delete mirror")
+}
+
+// ListMirrors returns the mirrors of a bridge with their statistics
+func (client *ovsClient) ListMirrors(brname string) ([]OvsMirror, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return nil, err
+	}
+	portNames := client.getPortNames()
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	mirrors := make([]OvsMirror, 0, len(bridge.MirrorUUIDs))
+	for _, uuid := range bridge.MirrorUUIDs {
+		row, ok := cache[mirrorTableName][uuid]
+		if !ok {
+			continue
+		}
+		mirror := OvsMirror{UUID: uuid}
+		mirror.readFromDBRow(&row, portNames)
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors, nil
+}
+
+func (mirror *OvsMirror) readFromDBRow(row *libovsdb.Row, portNames map[string]string) {
+	mirror.SelectSrcPorts = make([]string, 0)
+	mirror.SelectDstPorts = make([]string, 0)
+	mirror.SelectVLANs = make([]int, 0)
+	for field, value := range row.Fields {
+		switch field {
+		case "name":
+			mirror.Name = value.(string)
+		case "select_all":
+			mirror.SelectAll = value.(bool)
+		case "select_src_port":
+			for _, uuid := range uuidsFromValue(value) {
+				mirror.SelectSrcPorts = append(mirror.SelectSrcPorts, portNames[uuid])
+			}
+		case "select_dst_port":
+			for _, uuid := range uuidsFromValue(value) {
+				mirror.SelectDstPorts = append(mirror.SelectDstPorts, portNames[uuid])
+			}
+		case "select_vlan":
+			mirror.SelectVLANs = intsFromValue(value)
+		case "output_port":
+			if uuids := uuidsFromValue(value); len(uuids) == 1 {
+				mirror.OutputPort = portNames[uuids[0]]
+			}
+		case "output_vlan":
+			mirror.OutputVLAN, _ = intFromValue(value)
+		case "statistics":
+			if stats, ok := value.(libovsdb.OvsMap); ok {
+				mirror.Statistics.TxPackets, _ = intFromValue(stats.GoMap["tx_packets"])
+				mirror.Statistics.TxBytes, _ = intFromValue(stats.GoMap["tx_bytes"])
+			}
+		}
+	}
+}
+
+// newMirrorRow builds the mirror row to insert for a spec, the ports must
+// belong to the bridge
+func (client *ovsClient) newMirrorRow(bridge *OvsBridge, spec MirrorSpec) (map[string]interface{}, error) {
+	if (spec.OutputPort == "") == (spec.OutputVLAN == 0) {
+		return nil, fmt.Errorf("The mirror %s needs either an output port or an output vlan", spec.Name)
+	}
+	if spec.OutputVLAN < 0 || spec.OutputVLAN > 4095 {
+		return nil, fmt.Errorf("The mirror output vlan %d is invalid", spec.OutputVLAN)
+	}
+	if !spec.SelectAll && len(spec.SelectSrcPorts) == 0 && len(spec.SelectDstPorts) == 0 && len(spec.SelectVLANs) == 0 {
+		return nil, fmt.Errorf("The mirror %s doesn't select any traffic", spec.Name)
+	}
+	for _, vlan := range spec.SelectVLANs {
+		if vlan < 0 || vlan > 4095 {
+			return nil, fmt.Errorf("The mirror select vlan %d is invalid", vlan)
+		}
+	}
+	srcPorts, err := client.bridgePortUUIDs(bridge, spec.SelectSrcPorts)
+	if err != nil {
+		return nil, err
+	}
+	dstPorts, err := client.bridgePortUUIDs(bridge, spec.SelectDstPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	mirror := make(map[string]interface{})
+	mirror["name"] = spec.Name
+	mirror["select_all"] = spec.SelectAll
+	mirror["select_src_port"], _ = libovsdb.NewOvsSet(srcPorts)
+	mirror["select_dst_port"], _ = libovsdb.NewOvsSet(dstPorts)
+	if len(spec.SelectVLANs) != 0 {
+		mirror["select_vlan"], _ = libovsdb.NewOvsSet(spec.SelectVLANs)
+	}
+	if spec.OutputPort != "" {
+		outputPort, err := client.bridgePortUUIDs(bridge, []string{spec.OutputPort})
+		if err != nil {
+			return nil, err
+		}
+		mirror["output_port"] = outputPort[0]
+	} else {
+		mirror["output_vlan"] = spec.OutputVLAN
+	}
+	return mirror, nil
+}
+
+// bridgePortUUIDs resolves port names into the uuids of the bridge ports
+func (client *ovsClient) bridgePortUUIDs(bridge *OvsBridge, portnames []string) ([]libovsdb.UUID, error) {
+	uuids := make([]libovsdb.UUID, 0, len(portnames))
+	for _, portname := range portnames {
+		port, err := client.getPortByName(portname)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, uuid := range bridge.PortUUIDs {
+			if uuid == port.UUID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("The port %s doesn't belong to bridge %s", portname, bridge.Name)
+		}
+		uuids = append(uuids, libovsdb.UUID{GoUUID: port.UUID})
+	}
+	return uuids, nil
+}
+
+func (client *ovsClient) getMirrorUUID(bridge *OvsBridge, mirrorname string) (string, error) {
+	populateCacheLock.RLock()
+	defer populateCacheLock.RUnlock()
+	for _, uuid := range bridge.MirrorUUIDs {
+		if row, ok := cache[mirrorTableName][uuid]; ok && row.Fields["name"] == mirrorname {
+			return uuid, nil
+		}
+	}
+	return "", fmt.Errorf("The mirror %s doesn't exist on bridge %s", mirrorname, bridge.Name)
+}
+
+// getPortNames maps the port uuids to their names
+func (client *ovsClient) getPortNames() map[string]string {
+	portCacheUpdateLock.RLock()
+	defer portCacheUpdateLock.RUnlock()
+	names := make(map[string]string, len(client.portCache))
+	for uuid, port := range client.portCache {
+		names[uuid] = port.Name
+	}
+	return names
+}
@@ -0,0 +1,46 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestNewMirrorRow(t *testing.T) {
+	testClient := &ovsClient{portCache: map[string]*OvsPort{
+		"p1": {UUID: "p1", Name: "vm1"},
+		"p2": {UUID: "p2", Name: "analyser"},
+		"p3": {UUID: "p3", Name: "other"},
+	}}
+	bridge := &OvsBridge{Name: "br1", PortUUIDs: []string{"p1", "p2"}}
+	mirror, err := testClient.newMirrorRow(bridge, MirrorSpec{Name: "m1", SelectSrcPorts: []string{"vm1"}, OutputPort: "analyser"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirror["output_port"] != (libovsdb.UUID{GoUUID: "p2"}) {
+		t.Fatalf("The output port %v is incorrect", mirror["output_port"])
+	}
+	invalid := []MirrorSpec{
+		{Name: "m1", SelectAll: true},
+		{Name: "m1", SelectAll: true, OutputPort: "analyser", OutputVLAN: 10},
+		{Name: "m1", OutputVLAN: 10},
+		{Name: "m1", SelectSrcPorts: []string{"other"}, OutputVLAN: 10},
+	}
+	for _, spec := range invalid {
+		if _, err = testClient.newMirrorRow(bridge, spec); err == nil {
+			t.Fatalf("The mirror spec %+v should be invalid", spec)
+		}
+	}
+}
+
+func TestCreateMirror(t *testing.T) {
+	// TODO
+}
+
+func TestDeleteMirror(t *testing.T) {
+	// TODO
+}
+
+func TestListMirrors(t *testing.T) {
+	// TODO
+}
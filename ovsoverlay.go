@@ -0,0 +1,305 @@
+package goovs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	// overlayOwnerKey is the external_ids key marking the tunnel ports managed
+	// by SyncOverlay, its value is the owner of the overlay
+	overlayOwnerKey = "goovs-overlay-owner"
+	// overlayRemoteKey is the external_ids key holding the remote ip of a
+	// managed tunnel port
+	overlayRemoteKey = "goovs-overlay-remote-ip"
+	// defaultOverlayOwner owns the overlay when OverlaySpec.Owner is empty
+	defaultOverlayOwner = "goovs"
+)
+
+// overlayPortPrefixes keeps the tunnel port names within the 15 characters
+// allowed for a network device name
+var overlayPortPrefixes = map[string]string{
+	TunnelTypeVXLAN:     "vx",
+	TunnelTypeGeneve:    "gnv",
+	TunnelTypeGRE:       "gre",
+	TunnelTypeIP6GRE:    "gre6",
+	TunnelTypeERSPAN:    "ers",
+	TunnelTypeIP6ERSPAN: "ers6",
+	TunnelTypeSTT:       "stt",
+}
+
+// OverlaySpec describes the full mesh of tunnels of a node. Tunnel is the
+// template of the tunnel ports, its type defaults to vxlan and its remote and
+// local ips are set from the spec. The remote ip equal to LocalIP is skipped
+// so that the same peer list can be used on every node. Only the ports tagged
+// with Owner in their external_ids are changed or removed, and only the ones
+// of the tunnel type are removed
+type OverlaySpec struct {
+	Bridge    string
+	LocalIP   string
+	RemoteIPs []string
+	Tunnel    TunnelSpec
+	Owner     string
+	DryRun    bool
+}
+
+// OverlayChanges lists the tunnel ports by name. Updated ports are
+// recreated since their settings differ from the spec
+type OverlayChanges struct {
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+}
+
+// overlayPlan holds the operations computed by planOverlay
+type overlayPlan struct {
+	changes   *OverlayChanges
+	create    []PortSpec
+	deleteIDs []string
+}
+
+// SyncOverlay makes the tunnel ports of the bridge match the spec within a
+// single transaction, and returns the changes. With DryRun the changes are
+// computed but not applied
+func (client *ovsClient) SyncOverlay(spec OverlaySpec) (*OverlayChanges, error) {
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	bridgeExists, err := client.bridgeRowExists(spec.Bridge)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the bridge info")
+	} else if !bridgeExists {
+		return nil, fmt.Errorf("The bridge %s doesn't exist", spec.Bridge)
+	}
+	plan, err := client.planOverlay(spec)
+	if err != nil {
+		return nil, err
+	}
+	if spec.DryRun || (len(plan.create) == 0 && len(plan.deleteIDs) == 0) {
+		return plan.changes, nil
+	}
+
+	var operations []libovsdb.Operation
+	condition := libovsdb.NewCondition("name", "==", spec.Bridge)
+	deleteUUIDs := make([]libovsdb.UUID, 0, len(plan.deleteIDs))
+	for _, portUUID := range plan.deleteIDs {
+		operations = append(operations, libovsdb.Operation{
+			Op:    deleteOperation,
+			Table: portTableName,
+			Where: []interface{}{libovsdb.NewCondition("_uuid", "==", []string{"uuid", portUUID})},
+		})
+		deleteUUIDs = append(deleteUUIDs, libovsdb.UUID{GoUUID: portUUID})
+	}
+	if len(deleteUUIDs) != 0 {
+		// The deleted ports are removed from the bridge before the new ones
+		// are added, since a recreated port keeps its name
+		mutateSet, _ := libovsdb.NewOvsSet(deleteUUIDs)
+		operations = append(operations, libovsdb.Operation{
+			Op:        mutateOperation,
+			Table:     bridgeTableName,
+			Mutations: []interface{}{libovsdb.NewMutation("ports", deleteOperation, mutateSet)},
+			Where:     []interface{}{condition},
+		})
+	}
+
+	owner := overlayOwner(spec)
+	insertUUIDs := make([]libovsdb.UUID, 0, len(plan.create))
+	for index, portSpec := range plan.create {
+		intf, err := newInterfaceRowFromSpec(portSpec)
+		if err != nil {
+			return nil, err
+		}
+		namedPortUUID := fmt.Sprintf("goport%d", index)
+		namedInterfaceUUID := fmt.Sprintf("gointerface%d", index)
+		insertInterfaceOp, insertPortOp := newPortInsertOperations(portSpec.Name, nil, intf, namedPortUUID, namedInterfaceUUID)
+		insertPortOp.Row["external_ids"], _ = libovsdb.NewOvsMap(map[string]string{
+			overlayOwnerKey:  owner,
+			overlayRemoteKey: portSpec.Options["remote_ip"],
+		})
+		operations = append(operations, insertInterfaceOp, insertPortOp)
+		insertUUIDs = append(insertUUIDs, libovsdb.UUID{GoUUID: namedPortUUID})
+	}
+	if len(insertUUIDs) != 0 {
+		mutateSet, _ := libovsdb.NewOvsSet(insertUUIDs)
+		operations = append(operations, libovsdb.Operation{
+			Op:        mutateOperation,
+			Table:     bridgeTableName,
+			Mutations: []interface{}{libovsdb.NewMutation("ports", insertOperation, mutateSet)},
+			Where:     []interface{}{condition},
+		})
+	}
+	if err = client.transact(operations, "sync overlay"); err != nil {
+		return nil, err
+	}
+	return plan.changes, nil
+}
+
+// OverlayPortName returns the name of the tunnel port towards the remote
+// ip. IPv4 addresses are hex encoded, IPv6 addresses are hashed to fit the
+// device name length
+func OverlayPortName(tunnelType, remoteIP string) (string, error) {
+	if tunnelType == "" {
+		tunnelType = TunnelTypeVXLAN
+	}
+	prefix, ok := overlayPortPrefixes[tunnelType]
+	if !ok {
+		return "", fmt.Errorf("The tunnel type %s is not supported", tunnelType)
+	}
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return "", fmt.Errorf("The remote ip %s is invalid", remoteIP)
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return prefix + "-" + hex.EncodeToString(ipv4), nil
+	}
+	hash := fnv.New32a()
+	hash.Write(ip.To16())
+	return prefix + "-" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// planOverlay compares the desired tunnel ports with the ports of the bridge
+func (client *ovsClient) planOverlay(spec OverlaySpec) (*overlayPlan, error) {
+	localIP := net.ParseIP(spec.LocalIP)
+	if localIP == nil {
+		return nil, fmt.Errorf("The local ip %s is invalid", spec.LocalIP)
+	}
+	for _, remoteIP := range spec.RemoteIPs {
+		ip := net.ParseIP(remoteIP)
+		if ip == nil {
+			return nil, fmt.Errorf("The remote ip %s is invalid", remoteIP)
+		}
+		if (ip.To4() == nil) != (localIP.To4() == nil) {
+			return nil, fmt.Errorf("The remote ip %s and the local ip %s are not of the same address family", remoteIP, spec.LocalIP)
+		}
+	}
+	owner := overlayOwner(spec)
+	tunnelType := spec.Tunnel.Type
+	if tunnelType == "" {
+		tunnelType = TunnelTypeVXLAN
+	}
+
+	// the desired tunnel ports by name
+	desired := make(map[string]PortSpec)
+	for _, remoteIP := range spec.RemoteIPs {
+		if net.ParseIP(remoteIP).Equal(localIP) {
+			continue
+		}
+		tunnel := spec.Tunnel
+		tunnel.Type = tunnelType
+		tunnel.RemoteIP = remoteIP
+		tunnel.LocalIP = spec.LocalIP
+		name, err := OverlayPortName(tunnel.Type, remoteIP)
+		if err != nil {
+			return nil, err
+		}
+		options, err := tunnel.options()
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := desired[name]; ok && other.Options["remote_ip"] != remoteIP {
+			return nil, fmt.Errorf("The remote ips %s and %s map to the same port %s", other.Options["remote_ip"], remoteIP, name)
+		}
+		desired[name] = PortSpec{Name: name, Type: tunnel.Type, Options: options}
+	}
+
+	portUUIDs, err := client.findAllPortUUIDsOnBridge(spec.Bridge)
+	if err != nil {
+		return nil, err
+	}
+	plan := &overlayPlan{changes: &OverlayChanges{
+		Created:   make([]string, 0),
+		Updated:   make([]string, 0),
+		Deleted:   make([]string, 0),
+		Unchanged: make([]string, 0),
+	}}
+	existing := make(map[string]bool)
+	portCacheUpdateLock.RLock()
+	intfCacheUpdateLock.RLock()
+	for _, portUUID := range portUUIDs {
+		port, ok := client.portCache[portUUID]
+		if !ok {
+			continue
+		}
+		portSpec, wanted := desired[port.Name]
+		if port.ExternalIDs[overlayOwnerKey] != owner {
+			if wanted {
+				intfCacheUpdateLock.RUnlock()
+				portCacheUpdateLock.RUnlock()
+				return nil, fmt.Errorf("The port %s exists but isn't owned by %s", port.Name, owner)
+			}
+			continue
+		}
+		// the owned ports of the other tunnel types are left alone
+		if !wanted && !client.tunnelPortHasType(port, tunnelType) {
+			continue
+		}
+		existing[port.Name] = true
+		switch {
+		case !wanted:
+			plan.changes.Deleted = append(plan.changes.Deleted, port.Name)
+			plan.deleteIDs = append(plan.deleteIDs, portUUID)
+		case client.tunnelPortMatches(port, portSpec):
+			plan.changes.Unchanged = append(plan.changes.Unchanged, port.Name)
+		default:
+			plan.changes.Updated = append(plan.changes.Updated, port.Name)
+			plan.deleteIDs = append(plan.deleteIDs, portUUID)
+			plan.create = append(plan.create, portSpec)
+		}
+	}
+	intfCacheUpdateLock.RUnlock()
+	portCacheUpdateLock.RUnlock()
+
+	for name, portSpec := range desired {
+		if !existing[name] {
+			plan.changes.Created = append(plan.changes.Created, name)
+			plan.create = append(plan.create, portSpec)
+		}
+	}
+	sort.Strings(plan.changes.Created)
+	sort.Strings(plan.changes.Updated)
+	sort.Strings(plan.changes.Deleted)
+	sort.Strings(plan.changes.Unchanged)
+	sort.Slice(plan.create, func(i, j int) bool { return plan.create[i].Name < plan.create[j].Name })
+	return plan, nil
+}
+
+// tunnelPortMatches tells if the single interface of the port has the type
+// and the options of the spec, the caller holds the cache locks
+func (client *ovsClient) tunnelPortMatches(port *OvsPort, spec PortSpec) bool {
+	if len(port.IntfUUIDs) != 1 {
+		return false
+	}
+	intf, ok := client.interfaceCache[port.IntfUUIDs[0]]
+	if !ok {
+		return false
+	}
+	return intf.Type == spec.Type && reflect.DeepEqual(intf.Options, spec.Options)
+}
+
+// tunnelPortHasType tells if the port is named after the tunnel type or has
+// an interface of the type, the caller holds the cache locks
+func (client *ovsClient) tunnelPortHasType(port *OvsPort, tunnelType string) bool {
+	if strings.HasPrefix(port.Name, overlayPortPrefixes[tunnelType]+"-") {
+		return true
+	}
+	for _, uuid := range port.IntfUUIDs {
+		if intf, ok := client.interfaceCache[uuid]; ok && intf.Type == tunnelType {
+			return true
+		}
+	}
+	return false
+}
+
+func overlayOwner(spec OverlaySpec) string {
+	if spec.Owner == "" {
+		return defaultOverlayOwner
+	}
+	return spec.Owner
+}
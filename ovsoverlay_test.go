@@ -0,0 +1,131 @@
+package goovs
+
+import (
+	"testing"
+)
+
+func TestOverlayPortName(t *testing.T) {
+	name, err := OverlayPortName("", "192.0.2.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "vx-c000020a" {
+		t.Fatalf("The port name %s is incorrect", name)
+	}
+	name, err = OverlayPortName(TunnelTypeIP6ERSPAN, "2001:db8::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(name) != 13 || name[:5] != "ers6-" {
+		t.Fatalf("The port name %s is incorrect", name)
+	}
+	if other, _ := OverlayPortName(TunnelTypeIP6ERSPAN, "2001:db8:0::1"); other != name {
+		t.Fatalf("The port names %s and %s should be equal", name, other)
+	}
+	if _, err = OverlayPortName(TunnelTypeVXLAN, "host"); err == nil {
+		t.Fatal("The remote ip host should be invalid")
+	}
+	if _, err = OverlayPortName("lisp", "192.0.2.10"); err == nil {
+		t.Fatal("The tunnel type lisp should be invalid")
+	}
+}
+
+func newOverlayTestClient() *ovsClient {
+	owned := map[string]string{overlayOwnerKey: "node1"}
+	return &ovsClient{
+		bridgeCache: map[string]*OvsBridge{
+			"br0": {UUID: "br0", Name: "br0", PortUUIDs: []string{"p0", "p1", "p2", "p3", "p4"}},
+		},
+		portCache: map[string]*OvsPort{
+			"p0": {UUID: "p0", Name: "br0"},
+			"p1": {UUID: "p1", Name: "vx-c0000202", IntfUUIDs: []string{"i1"}, ExternalIDs: owned},
+			"p2": {UUID: "p2", Name: "vx-c0000203", IntfUUIDs: []string{"i2"}, ExternalIDs: owned},
+			"p3": {UUID: "p3", Name: "vx-c0000209", IntfUUIDs: []string{"i3"}, ExternalIDs: owned},
+			"p4": {UUID: "p4", Name: "vx-c000020a", IntfUUIDs: []string{"i4"}, ExternalIDs: map[string]string{overlayOwnerKey: "node2"}},
+		},
+		interfaceCache: map[string]*OvsInterface{
+			"i1": {UUID: "i1", Type: TunnelTypeVXLAN, Options: map[string]string{"remote_ip": "192.0.2.2", "local_ip": "192.0.2.1", "key": "100"}},
+			"i2": {UUID: "i2", Type: TunnelTypeVXLAN, Options: map[string]string{"remote_ip": "192.0.2.3", "local_ip": "192.0.2.1"}},
+			"i3": {UUID: "i3", Type: TunnelTypeVXLAN, Options: map[string]string{"remote_ip": "192.0.2.9", "local_ip": "192.0.2.1"}},
+			"i4": {UUID: "i4", Type: TunnelTypeVXLAN, Options: map[string]string{"remote_ip": "192.0.2.10", "local_ip": "192.0.2.1"}},
+		},
+	}
+}
+
+func TestPlanOverlay(t *testing.T) {
+	testClient := newOverlayTestClient()
+	spec := OverlaySpec{
+		Bridge:    "br0",
+		LocalIP:   "192.0.2.1",
+		RemoteIPs: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.2"},
+		Tunnel:    TunnelSpec{Key: "100"},
+		Owner:     "node1",
+	}
+	plan, err := testClient.planOverlay(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := plan.changes
+	if len(changes.Created) != 1 || changes.Created[0] != "vx-c0000204" {
+		t.Fatalf("The created ports %v are incorrect", changes.Created)
+	}
+	if len(changes.Updated) != 1 || changes.Updated[0] != "vx-c0000203" {
+		t.Fatalf("The updated ports %v are incorrect", changes.Updated)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0] != "vx-c0000209" {
+		t.Fatalf("The deleted ports %v are incorrect", changes.Deleted)
+	}
+	if len(changes.Unchanged) != 1 || changes.Unchanged[0] != "vx-c0000202" {
+		t.Fatalf("The unchanged ports %v are incorrect", changes.Unchanged)
+	}
+	if len(plan.create) != 2 || plan.create[0].Name != "vx-c0000203" || plan.create[1].Name != "vx-c0000204" {
+		t.Fatalf("The ports to create %+v are incorrect", plan.create)
+	}
+	if len(plan.deleteIDs) != 2 {
+		t.Fatalf("The ports to delete %v are incorrect", plan.deleteIDs)
+	}
+
+	// The port towards 192.0.2.10 belongs to another owner
+	spec.RemoteIPs = append(spec.RemoteIPs, "192.0.2.10")
+	if _, err = testClient.planOverlay(spec); err == nil {
+		t.Fatal("The port owned by node2 should not be replaced")
+	}
+	spec.LocalIP = "node1"
+	if _, err = testClient.planOverlay(spec); err == nil {
+		t.Fatal("The local ip node1 should be invalid")
+	}
+}
+
+func TestPlanOverlayTunnelTypes(t *testing.T) {
+	testClient := newOverlayTestClient()
+	testClient.bridgeCache["br0"].PortUUIDs = append(testClient.bridgeCache["br0"].PortUUIDs, "p5")
+	testClient.portCache["p5"] = &OvsPort{UUID: "p5", Name: "gnv-c0000205", IntfUUIDs: []string{"i5"}, ExternalIDs: map[string]string{overlayOwnerKey: "node1"}}
+	testClient.interfaceCache["i5"] = &OvsInterface{UUID: "i5", Type: TunnelTypeGeneve, Options: map[string]string{"remote_ip": "192.0.2.5", "local_ip": "192.0.2.1"}}
+	spec := OverlaySpec{Bridge: "br0", LocalIP: "192.0.2.1", Owner: "node1"}
+	plan, err := testClient.planOverlay(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.changes.Deleted) != 3 || plan.changes.Deleted[0] != "vx-c0000202" {
+		t.Fatalf("Only the vxlan ports should be deleted, got %v", plan.changes.Deleted)
+	}
+	spec.Tunnel.Type = TunnelTypeGeneve
+	if plan, err = testClient.planOverlay(spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.changes.Deleted) != 1 || plan.changes.Deleted[0] != "gnv-c0000205" {
+		t.Fatalf("Only the geneve port should be deleted, got %v", plan.changes.Deleted)
+	}
+}
+
+func TestPlanOverlayAddressFamily(t *testing.T) {
+	testClient := newOverlayTestClient()
+	spec := OverlaySpec{Bridge: "br0", LocalIP: "192.0.2.1", RemoteIPs: []string{"192.0.2.2", "2001:db8::2"}, Owner: "node1"}
+	if _, err := testClient.planOverlay(spec); err == nil {
+		t.Fatal("The IPv6 remote ip should be rejected with an IPv4 local ip")
+	}
+}
+
+func TestSyncOverlay(t *testing.T) {
+	// TODO
+}
@@ -2,16 +2,33 @@ package goovs
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/rocksolidlabs/libovsdb"
 )
 
 // OvsPort represents a ovs port structure
 type OvsPort struct {
-	UUID      string   `json:"_uuid"`
-	Name      string   `json:"name"`
-	IntfUUIDs []string `json:"interfaces"`
-	Tag       float64  `json:"tag"`
+	UUID            string            `json:"_uuid"`
+	Name            string            `json:"name"`
+	IntfUUIDs       []string          `json:"interfaces"`
+	Tag             float64           `json:"tag"`
+	HasTag          bool              `json:"has_tag"`
+	VlanMode        string            `json:"vlan_mode"`
+	Trunks          []int             `json:"trunks"`
+	CVLANs          []int             `json:"cvlans"`
+	QinQEthType     string            `json:"qinq-ethtype"`
+	OtherConfig     map[string]string `json:"other_config"`
+	ExternalIDs     map[string]string `json:"external_ids"`
+	FakeBridge      bool              `json:"fake_bridge"`
+	BondMode        string            `json:"bond_mode"`
+	LACP            string            `json:"lacp"`
+	BondUpdelay     int               `json:"bond_updelay"`
+	BondDowndelay   int               `json:"bond_downdelay"`
+	BondActiveSlave string            `json:"bond_active_slave"`
+	STPStatus       PortSTPStatus     `json:"status"`
+	RSTPStatus      PortRSTPStatus    `json:"rstp_status"`
 }
 
 // ReadFromDBRow is used to initialize the object from a row
@@ -19,20 +36,21 @@ func (port *OvsPort) ReadFromDBRow(row *libovsdb.Row) error {
 	if port.IntfUUIDs == nil {
 		port.IntfUUIDs = make([]string, 0)
 	}
+	if port.OtherConfig == nil {
+		port.OtherConfig = make(map[string]string)
+	}
+	if port.ExternalIDs == nil {
+		port.ExternalIDs = make(map[string]string)
+	}
 	for field, value := range row.Fields {
 		switch field {
 		case "name":
 			port.Name = value.(string)
 		case "tag":
-			switch value.(type) {
-			case float64:
-				port.Tag = value.(float64)
-				/*
-					case libovsdb.OvsSet:
-					for _, tags := value.(libovsdb.OvsSet).GoSet {
-
-					}
-				*/
+			// the optional column is an empty set when the port has no tag
+			if tags := intsFromValue(value); len(tags) == 1 {
+				port.Tag = float64(tags[0])
+				port.HasTag = true
 			}
 
 		case "interfaces":
@@ -44,6 +62,33 @@ func (port *OvsPort) ReadFromDBRow(row *libovsdb.Row) error {
 					port.IntfUUIDs = append(port.IntfUUIDs, uuids.(libovsdb.UUID).GoUUID)
 				}
 			}
+		case "vlan_mode":
+			port.VlanMode, _ = stringFromValue(value)
+		case "trunks":
+			port.Trunks = intsFromValue(value)
+		case "cvlans":
+			port.CVLANs = intsFromValue(value)
+		case "other_config":
+			port.OtherConfig = mapFromValue(value)
+			port.QinQEthType = port.OtherConfig["qinq-ethtype"]
+		case "external_ids":
+			port.ExternalIDs = mapFromValue(value)
+		case "fake_bridge":
+			port.FakeBridge = value.(bool)
+		case "bond_mode":
+			port.BondMode, _ = stringFromValue(value)
+		case "lacp":
+			port.LACP, _ = stringFromValue(value)
+		case "bond_updelay":
+			port.BondUpdelay, _ = intFromValue(value)
+		case "bond_downdelay":
+			port.BondDowndelay, _ = intFromValue(value)
+		case "bond_active_slave":
+			port.BondActiveSlave, _ = stringFromValue(value)
+		case "status":
+			port.STPStatus.readFromMap(mapFromValue(value))
+		case "rstp_status":
+			port.RSTPStatus.readFromMap(mapFromValue(value))
 		}
 	}
 	return nil
@@ -84,36 +129,28 @@ func (client *ovsClient) CreateVethPort(brname, portname string, vlantag int) er
 func (client *ovsClient) createPort(brname, portname string, vlantag int, intf map[string]interface{}) error {
 	portUpdateLock.Lock()
 	defer portUpdateLock.Unlock()
+	brname, vlantag, err := client.resolvePortBridge(brname, vlantag)
+	if err != nil {
+		return err
+	}
 	portExists, err := client.PortExistsOnBridge(portname, brname)
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve the port info due to %s", err.Error())
 	} else if portExists {
 		return nil
 	}
+	intftype, _ := intf["type"].(string)
+	client.warnInterfaceType(brname, portname, intftype)
 	namedPortUUID := "goport"
 	namedInterfaceUUID := "gointerface"
 
-	insertInterfaceOp := libovsdb.Operation{
-		Op:       "insert",
-		Table:    "Interface",
-		Row:      intf,
-		UUIDName: namedInterfaceUUID,
-	}
-
-	// port row to insert
-	port := make(map[string]interface{})
-	port["name"] = portname
-	port["interfaces"] = libovsdb.UUID{GoUUID: namedInterfaceUUID}
+	vlan := VlanSpec{}
 	if vlantag > 0 && vlantag <= 4095 {
-		port["tag"] = vlantag
+		vlan.Tag = vlantag
 	}
+	vlanColumns, _ := vlan.portColumns()
+	insertInterfaceOp, insertPortOp := newPortInsertOperations(portname, vlanColumns, intf, namedPortUUID, namedInterfaceUUID)
 
-	insertPortOp := libovsdb.Operation{
-		Op:       insertOperation,
-		Table:    portTableName,
-		Row:      port,
-		UUIDName: namedPortUUID,
-	}
 	// Inserting a Port row in Port table requires mutating the Bridge table
 	mutateUUID := []libovsdb.UUID{libovsdb.UUID{GoUUID: namedPortUUID}}
 	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
@@ -132,6 +169,33 @@ func (client *ovsClient) createPort(brname, portname string, vlantag int, intf m
 	return client.transact(operations, "create port")
 }
 
+// newPortInsertOperations builds the Interface and Port insert operations
+// for a port with a single interface, vlanColumns are the VLAN settings
+func newPortInsertOperations(portname string, vlanColumns map[string]interface{}, intf map[string]interface{}, namedPortUUID, namedInterfaceUUID string) (libovsdb.Operation, libovsdb.Operation) {
+	insertInterfaceOp := libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    interfaceTableName,
+		Row:      intf,
+		UUIDName: namedInterfaceUUID,
+	}
+
+	// port row to insert
+	port := make(map[string]interface{})
+	port["name"] = portname
+	port["interfaces"] = libovsdb.UUID{GoUUID: namedInterfaceUUID}
+	for column, value := range vlanColumns {
+		port[column] = value
+	}
+
+	insertPortOp := libovsdb.Operation{
+		Op:       insertOperation,
+		Table:    portTableName,
+		Row:      port,
+		UUIDName: namedPortUUID,
+	}
+	return insertInterfaceOp, insertPortOp
+}
+
 func (client *ovsClient) DeletePort(brname, portname string) error {
 	exists, err := client.PortExistsOnBridge(portname, brname)
 	if err != nil {
@@ -144,6 +208,10 @@ func (client *ovsClient) DeletePort(brname, portname string) error {
 	if err != nil {
 		return err
 	}
+	brname, _, err = client.resolvePortBridge(brname, 0)
+	if err != nil {
+		return err
+	}
 	return client.deletePortByUUID(brname, portUUID)
 }
 
@@ -175,11 +243,14 @@ func (client *ovsClient) deletePortByUUID(brname, portUUID string) error {
 	return client.transact(operations, "delete port")
 }
 
+// PortExistsOnBridge checks if a port is one of the ports
+// FindAllPortsOnBridge returns for the bridge
 func (client *ovsClient) PortExistsOnBridge(portname, brname string) (bool, error) {
-	portUUIDs, err := client.findAllPortUUIDsOnBridge(brname)
+	portUUIDs, err := client.listPortUUIDs(brname)
 	if err != nil {
-		return false, nil
-	} else if len(portUUIDs) == 0 {
+		return false, err
+	}
+	if len(portUUIDs) == 0 {
 		return false, nil
 	}
 	for _, portUUID := range portUUIDs {
@@ -200,8 +271,11 @@ func (client *ovsClient) portExistsByUUID(portUUID string) (bool, error) {
 	return ok, nil
 }
 
+// FindAllPortsOnBridge returns the names of the ports on a bridge. As with
+// ovs-vsctl, the ports of a fake bridge are the parent ports tagged with its
+// vlan, and they aren't listed on the parent
 func (client *ovsClient) FindAllPortsOnBridge(brname string) ([]string, error) {
-	portUUIDs, err := client.findAllPortUUIDsOnBridge(brname)
+	portUUIDs, err := client.listPortUUIDs(brname)
 	if err != nil {
 		return nil, err
 	}
@@ -219,6 +293,21 @@ func (client *ovsClient) FindAllPortsOnBridge(brname string) ([]string, error) {
 	return portNames, nil
 }
 
+// listPortUUIDs returns the ports of a bridge following ovs-vsctl
+// list-ports, i.e. the ports of the fake bridges are left out of their
+// parent and belong to the fake bridge instead
+func (client *ovsClient) listPortUUIDs(brname string) ([]string, error) {
+	portUUIDs, err := client.findAllPortUUIDsOnBridge(brname)
+	if err == nil {
+		return client.excludeFakeBridgePorts(portUUIDs), nil
+	}
+	parent, fakePort, fakeErr := client.getFakeBridge(brname)
+	if fakeErr != nil {
+		return nil, err
+	}
+	return client.fakeBridgePortUUIDs(parent, fakePort), nil
+}
+
 func (client *ovsClient) findAllPortUUIDsOnBridge(brname string) ([]string, error) {
 	bridgeCacheUpdateLock.RLock()
 	defer bridgeCacheUpdateLock.RUnlock()
@@ -231,6 +320,20 @@ func (client *ovsClient) findAllPortUUIDsOnBridge(brname string) ([]string, erro
 	return nil, fmt.Errorf("Bridge with name %s doesn't exist", brname)
 }
 
+// getBridgeNameByPortUUID returns the name of the bridge row holding a port
+func (client *ovsClient) getBridgeNameByPortUUID(portUUID string) (string, error) {
+	bridgeCacheUpdateLock.RLock()
+	defer bridgeCacheUpdateLock.RUnlock()
+	for _, bridge := range client.bridgeCache {
+		for _, uuid := range bridge.PortUUIDs {
+			if uuid == portUUID {
+				return bridge.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("The bridge of port with uuid %s doesn't exist", portUUID)
+}
+
 func (client *ovsClient) getPortNameByUUID(portUUID string) (string, error) {
 	portCacheUpdateLock.RLock()
 	defer portCacheUpdateLock.RUnlock()
@@ -252,28 +355,93 @@ func (client *ovsClient) getPortUUIDByName(portname string) (string, error) {
 	return "", fmt.Errorf("Unable to find the port uuid with name %s", portname)
 }
 
-func (client *ovsClient) UpdatePortTagByName(brname, portname string, vlantag int) error {
-	if vlantag < 0 || vlantag > 4095 {
-		return fmt.Errorf("The vlan tag value is not in valid range")
+// getPortByName returns a copy of the cached port
+func (client *ovsClient) getPortByName(portname string) (*OvsPort, error) {
+	portCacheUpdateLock.RLock()
+	defer portCacheUpdateLock.RUnlock()
+	for _, ovsport := range client.portCache {
+		if ovsport.Name == portname {
+			portObj := *ovsport
+			return &portObj, nil
+		}
 	}
-	portExist, err := client.PortExistsOnBridge(portname, brname)
-	if err != nil {
+	return nil, fmt.Errorf("Unable to find the port with name %s", portname)
+}
+
+// updatePortOtherConfig merges the keys into the other_config of a port
+func (client *ovsClient) updatePortOtherConfig(portname string, config map[string]string, action string) error {
+	mutations := newMapUpdateMutations("other_config", config)
+	if len(mutations) == 0 {
+		return nil
+	}
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	if _, err := client.getPortUUIDByName(portname); err != nil {
 		return err
 	}
-	if !portExist {
-		return fmt.Errorf("The port %s doesn't exists on bridge %s", portname, brname)
+	condition := libovsdb.NewCondition("name", "==", portname)
+	mutateOp := libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     portTableName,
+		Mutations: mutations,
+		Where:     []interface{}{condition},
 	}
-	portUUID, err := client.getPortUUIDByName(portname)
+	return client.transact([]libovsdb.Operation{mutateOp}, action)
+}
+
+// UpdatePortTagByName sets the vlan tag of a port, a tag of 0 is written as
+// such, use UntagPortByName to remove the tag
+func (client *ovsClient) UpdatePortTagByName(brname, portname string, vlantag int) error {
+	if err := validateVlanTag(vlantag); err != nil {
+		return err
+	}
+	portUUID, err := client.getPortUUIDOnBridge(brname, portname)
 	if err != nil {
 		return err
 	}
 	return client.updatePortTagByUUID(portUUID, vlantag)
 }
 
-func (client *ovsClient) updatePortTagByUUID(portUUID string, vlantag int) error {
+// UntagPortByName removes the vlan tag of a port, which makes it a trunk
+// port unless its vlan mode says otherwise
+func (client *ovsClient) UntagPortByName(brname, portname string) error {
+	portUUID, err := client.getPortUUIDOnBridge(brname, portname)
+	if err != nil {
+		return err
+	}
+	noTag, _ := libovsdb.NewOvsSet([]int{})
+	return client.updatePortTagColumn(portUUID, noTag, "untag port")
+}
+
+// validateVlanTag checks that the vlan tag is within [0, 4095]
+func validateVlanTag(vlantag int) error {
 	if vlantag < 0 || vlantag > 4095 {
 		return fmt.Errorf("The vlan tag value is not in valid range")
 	}
+	return nil
+}
+
+func (client *ovsClient) getPortUUIDOnBridge(brname, portname string) (string, error) {
+	portExist, err := client.PortExistsOnBridge(portname, brname)
+	if err != nil {
+		return "", err
+	}
+	if !portExist {
+		return "", fmt.Errorf("The port %s doesn't exists on bridge %s", portname, brname)
+	}
+	return client.getPortUUIDByName(portname)
+}
+
+func (client *ovsClient) updatePortTagByUUID(portUUID string, vlantag int) error {
+	if err := validateVlanTag(vlantag); err != nil {
+		return err
+	}
+	return client.updatePortTagColumn(portUUID, vlantag, "update port")
+}
+
+// updatePortTagColumn sets the tag column of a port, value is either a tag
+// or an empty set
+func (client *ovsClient) updatePortTagColumn(portUUID string, value interface{}, action string) error {
 	portUpdateLock.Lock()
 	defer portUpdateLock.Unlock()
 	portExist, err := client.portExistsByUUID(portUUID)
@@ -286,7 +454,7 @@ func (client *ovsClient) updatePortTagByUUID(portUUID string, vlantag int) error
 	updateCondition := libovsdb.NewCondition("_uuid", "==", []string{"uuid", portUUID})
 	// port row to update
 	port := make(map[string]interface{})
-	port["tag"] = vlantag
+	port["tag"] = value
 
 	updateOp := libovsdb.Operation{
 		Op:    updateOperation,
@@ -295,5 +463,294 @@ func (client *ovsClient) updatePortTagByUUID(portUUID string, vlantag int) error
 		Where: []interface{}{updateCondition},
 	}
 	operations := []libovsdb.Operation{updateOp}
-	return client.transact(operations, "update port")
+	return client.transact(operations, action)
+}
+
+// PortSpec describes a single port to be created by CreatePorts. The Type
+// can be internal, system, patch, dpdk, dpdkvhostuser, dpdkvhostuserclient
+// or a tunnel type; an empty type means a system port like in ovsdb.
+// PeerName is only used by patch ports, Options holds the interface options
+// such as dpdk-devargs, or the tunnel options built from a TunnelSpec.
+// The VLAN settings are described by VlanSpec, HasVlanTag is only needed
+// for the vlan 0. Patch ports ignore them
+type PortSpec struct {
+	Name        string
+	Type        string
+	VlanTag     int
+	HasVlanTag  bool
+	VlanMode    string
+	Trunks      []int
+	CVLANs      []int
+	QinQEthType string
+	PeerName    string
+	Options     map[string]string
+}
+
+// vlanSpec returns the VLAN settings of the port
+func (spec *PortSpec) vlanSpec() VlanSpec {
+	if spec.Type == "patch" {
+		return VlanSpec{}
+	}
+	return VlanSpec{
+		Mode:        spec.VlanMode,
+		Tag:         spec.VlanTag,
+		HasTag:      spec.HasVlanTag,
+		Trunks:      spec.Trunks,
+		CVLANs:      spec.CVLANs,
+		QinQEthType: spec.QinQEthType,
+	}
+}
+
+// PortErrors holds the errors of a bulk port operation keyed by port name
+type PortErrors map[string]error
+
+func (portErrors PortErrors) Error() string {
+	names := make([]string, 0, len(portErrors))
+	for name := range portErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", name, portErrors[name].Error()))
+	}
+	return fmt.Sprintf("%d port(s) failed: %s", len(portErrors), strings.Join(msgs, "; "))
+}
+
+// newInterfaceRowFromSpec builds the interface row to insert for a port spec
+func newInterfaceRowFromSpec(spec PortSpec) (map[string]interface{}, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("The port name is invalid")
+	}
+	vlan := spec.vlanSpec()
+	if err := vlan.validate(); err != nil {
+		return nil, err
+	}
+	intf := make(map[string]interface{})
+	intf["name"] = spec.Name
+	switch spec.Type {
+	case "internal":
+		intf["type"] = `internal`
+	case "", "system":
+		intf["type"] = `system`
+	case "patch":
+		if spec.PeerName == "" {
+			return nil, fmt.Errorf("The patch port %s has no peer", spec.Name)
+		}
+		intf["type"] = `patch`
+		options := make(map[string]interface{})
+		options["peer"] = spec.PeerName
+		intf["options"], _ = libovsdb.NewOvsMap(options)
+	case dpdkInterfaceType, dpdkVhostUserInterfaceType, dpdkVhostUserClientInterfaceType:
+		required := map[string]string{
+			dpdkInterfaceType:                "dpdk-devargs",
+			dpdkVhostUserClientInterfaceType: "vhost-server-path",
+		}[spec.Type]
+		if required != "" && spec.Options[required] == "" {
+			return nil, fmt.Errorf("The %s port %s has no %s option", spec.Type, spec.Name, required)
+		}
+		intf["type"] = spec.Type
+		if len(spec.Options) != 0 {
+			intf["options"], _ = libovsdb.NewOvsMap(spec.Options)
+		}
+	default:
+		if !isTunnelType(spec.Type) {
+			return nil, fmt.Errorf("The port type %s is not supported", spec.Type)
+		}
+		// the tunnel options are validated like a TunnelSpec
+		tunnel, err := newTunnelSpecFromOptions(spec.Type, spec.Options)
+		if err != nil {
+			return nil, err
+		}
+		options, err := tunnel.options()
+		if err != nil {
+			return nil, fmt.Errorf("The %s port %s is invalid: %s", spec.Type, spec.Name, err.Error())
+		}
+		intf["type"] = spec.Type
+		intf["options"], _ = libovsdb.NewOvsMap(options)
+	}
+	return intf, nil
+}
+
+// CreatePorts creates several ports on a bridge within a single transaction.
+// Ports which already exist on the bridge are skipped. Since the transaction
+// is atomic, none of the ports is created if ovsdb rejects one of them. The
+// returned error is a PortErrors whenever the failure can be tied to ports
+func (client *ovsClient) CreatePorts(brname string, specs []PortSpec) error {
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	// the ports of a fake bridge are held by its parent
+	bridgeName, _, err := client.resolvePortBridge(brname, 0)
+	if err != nil {
+		return err
+	}
+
+	// PortErrors is keyed by port name, so the names must be unique
+	if err = validatePortSpecNames(specs); err != nil {
+		return err
+	}
+
+	portErrors := make(PortErrors)
+	var operations []libovsdb.Operation
+	var opOwners, portnames []string
+	var mutateUUID []libovsdb.UUID
+	for index, spec := range specs {
+		// the ports of a fake bridge are tagged with its vlan
+		if spec.Type != "patch" {
+			if _, spec.VlanTag, err = client.resolvePortBridge(brname, spec.VlanTag); err != nil {
+				portErrors[spec.Name] = err
+				continue
+			}
+		}
+		intf, err := newInterfaceRowFromSpec(spec)
+		if err != nil {
+			portErrors[spec.Name] = err
+			continue
+		}
+		portExists, err := client.PortExistsOnBridge(spec.Name, brname)
+		if err != nil {
+			portErrors[spec.Name] = fmt.Errorf("Failed to retrieve the port info due to %s", err.Error())
+			continue
+		} else if portExists {
+			continue
+		}
+		client.warnInterfaceType(bridgeName, spec.Name, intf["type"].(string))
+		namedPortUUID := fmt.Sprintf("goport%d", index)
+		namedInterfaceUUID := fmt.Sprintf("gointerface%d", index)
+		// the vlan settings are validated by newInterfaceRowFromSpec
+		vlan := spec.vlanSpec()
+		vlanColumns, _ := vlan.portColumns()
+		insertInterfaceOp, insertPortOp := newPortInsertOperations(spec.Name, vlanColumns, intf, namedPortUUID, namedInterfaceUUID)
+		if vlan.QinQEthType != "" {
+			insertPortOp.Row["other_config"], _ = libovsdb.NewOvsMap(map[string]string{"qinq-ethtype": vlan.QinQEthType})
+		}
+		operations = append(operations, insertInterfaceOp, insertPortOp)
+		opOwners = append(opOwners, spec.Name, spec.Name)
+		portnames = append(portnames, spec.Name)
+		mutateUUID = append(mutateUUID, libovsdb.UUID{GoUUID: namedPortUUID})
+	}
+
+	if len(mutateUUID) != 0 {
+		// Inserting the Port rows requires a single mutation of the Bridge table
+		mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
+		mutation := libovsdb.NewMutation("ports", insertOperation, mutateSet)
+		condition := libovsdb.NewCondition("name", "==", bridgeName)
+		mutateOp := libovsdb.Operation{
+			Op:        mutateOperation,
+			Table:     bridgeTableName,
+			Mutations: []interface{}{mutation},
+			Where:     []interface{}{condition},
+		}
+		operations = append(operations, mutateOp)
+		if index, err := client.transactWithIndex(operations, "create ports"); err != nil {
+			blameAbortedTransaction(portErrors, portnames, opOwners, index, err)
+		}
+	}
+
+	if len(portErrors) != 0 {
+		return portErrors
+	}
+	return nil
+}
+
+// DeletePorts deletes several ports from a bridge within a single transaction.
+// Ports which don't exist on the bridge are skipped. The returned error is a
+// PortErrors whenever the failure can be tied to ports
+func (client *ovsClient) DeletePorts(brname string, portnames []string) error {
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+
+	// the ports of a fake bridge are held by its parent
+	bridgeName, _, err := client.resolvePortBridge(brname, 0)
+	if err != nil {
+		return err
+	}
+
+	portErrors := make(PortErrors)
+	seen := make(map[string]bool)
+	var operations []libovsdb.Operation
+	var opOwners, deleted []string
+	var mutateUUID []libovsdb.UUID
+	for _, portname := range portnames {
+		if seen[portname] {
+			continue
+		}
+		seen[portname] = true
+		exists, err := client.PortExistsOnBridge(portname, brname)
+		if err != nil {
+			portErrors[portname] = err
+			continue
+		} else if !exists {
+			continue
+		}
+		portUUID, err := client.getPortUUIDByName(portname)
+		if err != nil {
+			portErrors[portname] = err
+			continue
+		}
+		portDeleteCondition := libovsdb.NewCondition("_uuid", "==", []string{"uuid", portUUID})
+		portDeleteOp := libovsdb.Operation{
+			Op:    deleteOperation,
+			Table: portTableName,
+			Where: []interface{}{portDeleteCondition},
+		}
+		operations = append(operations, portDeleteOp)
+		opOwners = append(opOwners, portname)
+		deleted = append(deleted, portname)
+		mutateUUID = append(mutateUUID, libovsdb.UUID{GoUUID: portUUID})
+	}
+
+	if len(mutateUUID) != 0 {
+		// Deleting the Port rows requires a single mutation of the Bridge table
+		mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
+		mutation := libovsdb.NewMutation("ports", deleteOperation, mutateSet)
+		condition := libovsdb.NewCondition("name", "==", bridgeName)
+		mutateOp := libovsdb.Operation{
+			Op:        mutateOperation,
+			Table:     bridgeTableName,
+			Mutations: []interface{}{mutation},
+			Where:     []interface{}{condition},
+		}
+		operations = append(operations, mutateOp)
+		if index, err := client.transactWithIndex(operations, "delete ports"); err != nil {
+			blameAbortedTransaction(portErrors, deleted, opOwners, index, err)
+		}
+	}
+
+	if len(portErrors) != 0 {
+		return portErrors
+	}
+	return nil
+}
+
+// validatePortSpecNames rejects the specs with an empty or duplicate name
+func validatePortSpecNames(specs []PortSpec) error {
+	seen := make(map[string]bool)
+	for index, spec := range specs {
+		if spec.Name == "" {
+			return fmt.Errorf("The port spec %d has no name", index)
+		}
+		if seen[spec.Name] {
+			return fmt.Errorf("The port %s is specified more than once", spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+	return nil
+}
+
+// blameAbortedTransaction records err against the port owning the failed
+// operation, the other ports of the transaction are reported as aborted. If
+// the failure can't be tied to a port, all of them get err
+func blameAbortedTransaction(portErrors PortErrors, portnames, opOwners []string, index int, err error) {
+	culprit := ""
+	if index >= 0 && index < len(opOwners) {
+		culprit = opOwners[index]
+	}
+	for _, portname := range portnames {
+		if culprit == "" || portname == culprit {
+			portErrors[portname] = err
+		} else {
+			portErrors[portname] = fmt.Errorf("The port %s is not processed since the transaction is aborted", portname)
+		}
+	}
 }
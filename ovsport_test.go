@@ -1,11 +1,28 @@
 package goovs
 
 import (
+	"fmt"
 	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
 )
 
 func TestPortReadFromDBRow(t *testing.T) {
-	// TODO
+	noTag, _ := libovsdb.NewOvsSet([]int{})
+	untagged := &OvsPort{}
+	if err := untagged.ReadFromDBRow(&libovsdb.Row{Fields: map[string]interface{}{"tag": *noTag}}); err != nil {
+		t.Fatal(err)
+	}
+	if untagged.HasTag {
+		t.Fatal("The port without tag should not report a tag")
+	}
+	tagged := &OvsPort{}
+	if err := tagged.ReadFromDBRow(&libovsdb.Row{Fields: map[string]interface{}{"tag": float64(0)}}); err != nil {
+		t.Fatal(err)
+	}
+	if !tagged.HasTag || tagged.Tag != 0 {
+		t.Fatalf("The port with tag 0 is read as %+v", tagged)
+	}
 }
 
 func TestCreateInternalPort(t *testing.T) {
@@ -63,3 +80,89 @@ func TestUpdatePortTagByName(t *testing.T) {
 func TestUpdatePortTagByUUID(t *testing.T) {
 	// TODO
 }
+
+func TestUntagPortByName(t *testing.T) {
+	// TODO
+}
+
+func TestNewInterfaceRowFromSpec(t *testing.T) {
+	intf, err := newInterfaceRowFromSpec(PortSpec{Name: "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intf["type"] != "system" {
+		t.Fatalf("The interface type %v is incorrect", intf["type"])
+	}
+	if _, err = newInterfaceRowFromSpec(PortSpec{Name: "p2", Type: "patch"}); err == nil {
+		t.Fatal("Patch port without peer should be rejected")
+	}
+	if _, err = newInterfaceRowFromSpec(PortSpec{Name: "p3", VlanTag: 4096}); err == nil {
+		t.Fatal("Out of range vlan tag should be rejected")
+	}
+	if _, err = newInterfaceRowFromSpec(PortSpec{Name: "p4", Type: "unknown"}); err == nil {
+		t.Fatal("Unknown port type should be rejected")
+	}
+	if _, err = newInterfaceRowFromSpec(PortSpec{Name: "p5", Type: "dpdk"}); err == nil {
+		t.Fatal("Dpdk port without devargs should be rejected")
+	}
+	for _, options := range []map[string]string{
+		{"local_ip": "192.0.2.1"},
+		{"remote_ip": "192.0.2.2", "key": "16777216"},
+		{"remote_ip": "192.0.2.2", "dst_port": "70000"},
+	} {
+		if _, err = newInterfaceRowFromSpec(PortSpec{Name: "p6", Type: TunnelTypeVXLAN, Options: options}); err == nil {
+			t.Fatalf("The vxlan options %v should be rejected", options)
+		}
+	}
+}
+
+func TestValidatePortSpecNames(t *testing.T) {
+	if err := validatePortSpecNames([]PortSpec{{Name: "p1"}, {Name: "p2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validatePortSpecNames([]PortSpec{{Name: "p1"}, {Name: ""}}); err == nil {
+		t.Fatal("A spec without name should be rejected")
+	}
+	if err := validatePortSpecNames([]PortSpec{{Name: "p1"}, {Name: "p1"}}); err == nil {
+		t.Fatal("A duplicate port name should be rejected")
+	}
+}
+
+func TestBlameAbortedTransaction(t *testing.T) {
+	portErrors := make(PortErrors)
+	opOwners := []string{"p1", "p1", "p2", "p2"}
+	failure := fmt.Errorf("constraint violation")
+	blameAbortedTransaction(portErrors, []string{"p1", "p2"}, opOwners, 2, failure)
+	if portErrors["p2"] != failure {
+		t.Fatalf("The failure should be reported on p2, got %v", portErrors["p2"])
+	}
+	if portErrors["p1"] == nil || portErrors["p1"] == failure {
+		t.Fatalf("p1 should be reported as aborted, got %v", portErrors["p1"])
+	}
+
+	portErrors = make(PortErrors)
+	blameAbortedTransaction(portErrors, []string{"p1", "p2"}, opOwners, 4, failure)
+	if portErrors["p1"] != failure || portErrors["p2"] != failure {
+		t.Fatalf("The failure should be reported on all ports, got %v", portErrors)
+	}
+}
+
+func TestCreatePorts(t *testing.T) {
+	// TODO
+}
+
+func TestDeletePorts(t *testing.T) {
+	// TODO
+}
+
+func TestGetBridgeNameByPortUUID(t *testing.T) {
+	testClient := &ovsClient{bridgeCache: map[string]*OvsBridge{
+		"br0": {UUID: "br0", Name: "br0", PortUUIDs: []string{"p0", "p1"}},
+	}}
+	if brname, err := testClient.getBridgeNameByPortUUID("p1"); err != nil || brname != "br0" {
+		t.Fatalf("The port p1 should be on br0, got %s, %v", brname, err)
+	}
+	if _, err := testClient.getBridgeNameByPortUUID("p2"); err == nil {
+		t.Fatal("The port p2 is on no bridge")
+	}
+}
@@ -0,0 +1,500 @@
+package goovs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	integerType = "integer"
+	realType    = "real"
+	booleanType = "boolean"
+	stringType  = "string"
+	uuidType    = "uuid"
+)
+
+// ovsSchema is the part of the database schema used to validate operations
+// before they are sent to ovsdb-server
+type ovsSchema struct {
+	tables map[string]map[string]*columnType
+}
+
+// columnType describes the type of a column as defined in RFC 7047
+type columnType struct {
+	key   *atomType
+	value *atomType
+	min   int
+	max   int
+}
+
+// atomType describes the base type of the elements of a column
+type atomType struct {
+	kind       string
+	enum       []interface{}
+	minInteger int64
+	maxInteger int64
+	minReal    float64
+	maxReal    float64
+	minLength  int
+	maxLength  int
+	refTable   string
+}
+
+// newOvsSchema parses the database schema returned by ovsdb-server
+func newOvsSchema(dbSchema *libovsdb.DatabaseSchema) (*ovsSchema, error) {
+	if dbSchema == nil {
+		return nil, fmt.Errorf("The database schema is empty")
+	}
+	schema := &ovsSchema{tables: make(map[string]map[string]*columnType)}
+	for tableName, table := range dbSchema.Tables {
+		columns := make(map[string]*columnType)
+		for columnName, column := range table.Columns {
+			colType, err := parseColumnType(column.Type)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse the type of column %s.%s due to %s", tableName, columnName, err.Error())
+			}
+			columns[columnName] = colType
+		}
+		// Every table has the implicit _uuid and _version columns
+		columns["_uuid"] = &columnType{key: newAtomType(uuidType), min: 1, max: 1}
+		columns["_version"] = &columnType{key: newAtomType(uuidType), min: 1, max: 1}
+		schema.tables[tableName] = columns
+	}
+	return schema, nil
+}
+
+func newAtomType(kind string) *atomType {
+	return &atomType{
+		kind:       kind,
+		minInteger: math.MinInt64,
+		maxInteger: math.MaxInt64,
+		minReal:    -math.MaxFloat64,
+		maxReal:    math.MaxFloat64,
+		maxLength:  math.MaxInt32,
+	}
+}
+
+func parseColumnType(value interface{}) (*columnType, error) {
+	switch value.(type) {
+	case string:
+		key, err := parseAtomType(value)
+		if err != nil {
+			return nil, err
+		}
+		return &columnType{key: key, min: 1, max: 1}, nil
+	case map[string]interface{}:
+		fields := value.(map[string]interface{})
+		colType := &columnType{min: 1, max: 1}
+		var err error
+		if colType.key, err = parseAtomType(fields["key"]); err != nil {
+			return nil, err
+		}
+		if valueType, ok := fields["value"]; ok {
+			if colType.value, err = parseAtomType(valueType); err != nil {
+				return nil, err
+			}
+		}
+		if min, ok := fields["min"].(float64); ok {
+			colType.min = int(min)
+		}
+		switch fields["max"].(type) {
+		case float64:
+			colType.max = int(fields["max"].(float64))
+		case string:
+			if fields["max"].(string) != "unlimited" {
+				return nil, fmt.Errorf("invalid max value %q", fields["max"])
+			}
+			colType.max = math.MaxInt32
+		}
+		return colType, nil
+	}
+	return nil, fmt.Errorf("unknown type %v", value)
+}
+
+func parseAtomType(value interface{}) (*atomType, error) {
+	switch value.(type) {
+	case string:
+		return checkAtomKind(newAtomType(value.(string)))
+	case map[string]interface{}:
+		fields := value.(map[string]interface{})
+		kind, _ := fields["type"].(string)
+		atom := newAtomType(kind)
+		if enum, ok := fields["enum"]; ok {
+			atom.enum = parseEnum(enum)
+		}
+		if min, ok := fields["minInteger"].(float64); ok {
+			atom.minInteger = int64(min)
+		}
+		if max, ok := fields["maxInteger"].(float64); ok {
+			atom.maxInteger = int64(max)
+		}
+		if min, ok := fields["minReal"].(float64); ok {
+			atom.minReal = min
+		}
+		if max, ok := fields["maxReal"].(float64); ok {
+			atom.maxReal = max
+		}
+		if min, ok := fields["minLength"].(float64); ok {
+			atom.minLength = int(min)
+		}
+		if max, ok := fields["maxLength"].(float64); ok {
+			atom.maxLength = int(max)
+		}
+		atom.refTable, _ = fields["refTable"].(string)
+		return checkAtomKind(atom)
+	}
+	return nil, fmt.Errorf("unknown base type %v", value)
+}
+
+func checkAtomKind(atom *atomType) (*atomType, error) {
+	switch atom.kind {
+	case integerType, realType, booleanType, stringType, uuidType:
+		return atom, nil
+	}
+	return nil, fmt.Errorf("unknown atomic type %q", atom.kind)
+}
+
+// parseEnum reads an enum which is either a single atom or ["set", [atoms]]
+func parseEnum(value interface{}) []interface{} {
+	if set, ok := value.([]interface{}); ok && len(set) == 2 && set[0] == "set" {
+		if elems, ok := set[1].([]interface{}); ok {
+			return elems
+		}
+	}
+	return []interface{}{value}
+}
+
+// validateOperation checks the columns and values used by an operation
+func (schema *ovsSchema) validateOperation(operation libovsdb.Operation) error {
+	switch operation.Op {
+	case insertOperation, updateOperation, mutateOperation, deleteOperation, selectOperation:
+	default:
+		return nil
+	}
+	columns, ok := schema.tables[operation.Table]
+	if !ok {
+		return fmt.Errorf("the table %s doesn't exist", operation.Table)
+	}
+	getColumn := func(column string) (*columnType, error) {
+		colType, ok := columns[column]
+		if !ok {
+			return nil, fmt.Errorf("the column %s.%s doesn't exist", operation.Table, column)
+		}
+		return colType, nil
+	}
+
+	for _, column := range sortedKeys(operation.Row) {
+		colType, err := getColumn(column)
+		if err != nil {
+			return err
+		}
+		if err = colType.validateValue(operation.Row[column]); err != nil {
+			return fmt.Errorf("the value of column %s.%s is invalid: %s", operation.Table, column, err.Error())
+		}
+	}
+	for _, cond := range operation.Where {
+		fields, ok := cond.([]interface{})
+		if !ok || len(fields) != 3 {
+			return fmt.Errorf("the condition %v on table %s is malformed", cond, operation.Table)
+		}
+		column, _ := fields[0].(string)
+		function, _ := fields[1].(string)
+		colType, err := getColumn(column)
+		if err != nil {
+			return err
+		}
+		if err = colType.validateCondition(function, fields[2]); err != nil {
+			return fmt.Errorf("the condition on column %s.%s is invalid: %s", operation.Table, column, err.Error())
+		}
+	}
+	for _, mut := range operation.Mutations {
+		fields, ok := mut.([]interface{})
+		if !ok || len(fields) != 3 {
+			return fmt.Errorf("the mutation %v on table %s is malformed", mut, operation.Table)
+		}
+		column, _ := fields[0].(string)
+		mutator, _ := fields[1].(string)
+		colType, err := getColumn(column)
+		if err != nil {
+			return err
+		}
+		if err = colType.validateMutation(mutator, fields[2]); err != nil {
+			return fmt.Errorf("the mutation of column %s.%s is invalid: %s", operation.Table, column, err.Error())
+		}
+	}
+	for _, column := range operation.Columns {
+		if _, err := getColumn(column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (colType *columnType) isMap() bool {
+	return colType.value != nil
+}
+
+// validateValue checks a complete column value, including the number of
+// elements it holds
+func (colType *columnType) validateValue(value interface{}) error {
+	switch value.(type) {
+	case libovsdb.OvsMap, *libovsdb.OvsMap:
+		if !colType.isMap() {
+			return fmt.Errorf("a map is given but the column is not a map")
+		}
+		goMap := toGoMap(value)
+		if err := colType.checkSize(len(goMap)); err != nil {
+			return err
+		}
+		return colType.validateMapElems(goMap)
+	case libovsdb.OvsSet, *libovsdb.OvsSet:
+		goSet := toGoSet(value)
+		if colType.isMap() && len(goSet) != 0 {
+			return fmt.Errorf("a set is given but the column is a map")
+		}
+		if err := colType.checkSize(len(goSet)); err != nil {
+			return err
+		}
+		return colType.validateSetElems(goSet)
+	}
+	if colType.isMap() {
+		return fmt.Errorf("%v is given but the column is a map", value)
+	}
+	if err := colType.checkSize(1); err != nil {
+		return err
+	}
+	return colType.key.validateAtom(value)
+}
+
+// validateMutation checks the argument of a mutation, the resulting size of
+// the column can only be checked by ovsdb-server
+func (colType *columnType) validateMutation(mutator string, value interface{}) error {
+	switch mutator {
+	case "+=", "-=", "*=", "/=", "%=":
+		if colType.isMap() {
+			return fmt.Errorf("the mutator %s can't be applied on a map", mutator)
+		}
+		if colType.key.kind != integerType && colType.key.kind != realType {
+			return fmt.Errorf("the mutator %s can't be applied on %s", mutator, colType.key.kind)
+		}
+		return colType.key.validateAtom(value)
+	case insertOperation, deleteOperation:
+		switch value.(type) {
+		case libovsdb.OvsMap, *libovsdb.OvsMap:
+			if !colType.isMap() {
+				return fmt.Errorf("a map is given but the column is not a map")
+			}
+			return colType.validateMapElems(toGoMap(value))
+		case libovsdb.OvsSet, *libovsdb.OvsSet:
+			if colType.isMap() && mutator == insertOperation {
+				return fmt.Errorf("a set is given but the column is a map")
+			}
+			// Deleting from a map with a set removes the given keys
+			return colType.validateSetElems(toGoSet(value))
+		}
+		if colType.isMap() {
+			return fmt.Errorf("%v is given but the column is a map", value)
+		}
+		return colType.key.validateAtom(value)
+	}
+	return fmt.Errorf("the mutator %q is unknown", mutator)
+}
+
+// validateCondition checks the value a column is compared with
+func (colType *columnType) validateCondition(function string, value interface{}) error {
+	switch function {
+	case "==", "!=":
+		return colType.validateValue(value)
+	case "includes", "excludes":
+		// The value may hold fewer elements than the column requires
+		subset := *colType
+		subset.min = 0
+		return subset.validateValue(value)
+	case "<", "<=", ">", ">=":
+		if colType.isMap() || colType.max != 1 || (colType.key.kind != integerType && colType.key.kind != realType) {
+			return fmt.Errorf("the function %s can only be applied on an integer or a real", function)
+		}
+		return colType.key.validateAtom(value)
+	}
+	return fmt.Errorf("the function %q is unknown", function)
+}
+
+func (colType *columnType) checkSize(size int) error {
+	if size < colType.min || size > colType.max {
+		if colType.max == math.MaxInt32 {
+			return fmt.Errorf("%d element(s) given but at least %d expected", size, colType.min)
+		}
+		return fmt.Errorf("%d element(s) given but %d to %d expected", size, colType.min, colType.max)
+	}
+	return nil
+}
+
+func (colType *columnType) validateSetElems(goSet []interface{}) error {
+	for _, elem := range goSet {
+		if err := colType.key.validateAtom(elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (colType *columnType) validateMapElems(goMap map[interface{}]interface{}) error {
+	for key, value := range goMap {
+		if err := colType.key.validateAtom(key); err != nil {
+			return fmt.Errorf("key %v: %s", key, err.Error())
+		}
+		if err := colType.value.validateAtom(value); err != nil {
+			return fmt.Errorf("value of key %v: %s", key, err.Error())
+		}
+	}
+	return nil
+}
+
+// validateAtom checks a single element against the base type
+func (atom *atomType) validateAtom(value interface{}) error {
+	switch atom.kind {
+	case integerType:
+		integer, ok := toInteger(value)
+		if !ok {
+			return fmt.Errorf("%v is not an integer", value)
+		}
+		if integer < atom.minInteger || integer > atom.maxInteger {
+			return fmt.Errorf("%d is not in range %d to %d", integer, atom.minInteger, atom.maxInteger)
+		}
+	case realType:
+		real, ok := toReal(value)
+		if !ok {
+			return fmt.Errorf("%v is not a real", value)
+		}
+		if real < atom.minReal || real > atom.maxReal {
+			return fmt.Errorf("%g is not in range %g to %g", real, atom.minReal, atom.maxReal)
+		}
+	case booleanType:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v is not a boolean", value)
+		}
+	case stringType:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", value)
+		}
+		length := utf8.RuneCountInString(str)
+		if length < atom.minLength || length > atom.maxLength {
+			return fmt.Errorf("the length of %q is not in range %d to %d", str, atom.minLength, atom.maxLength)
+		}
+	case uuidType:
+		switch value.(type) {
+		case libovsdb.UUID:
+		case []string:
+			if pair := value.([]string); len(pair) != 2 || (pair[0] != "uuid" && pair[0] != "named-uuid") {
+				return fmt.Errorf("%v is not a uuid", value)
+			}
+		default:
+			return fmt.Errorf("%v is not a uuid", value)
+		}
+	}
+	return atom.validateEnum(value)
+}
+
+func (atom *atomType) validateEnum(value interface{}) error {
+	if len(atom.enum) == 0 {
+		return nil
+	}
+	for _, allowed := range atom.enum {
+		if atomEqual(allowed, value) {
+			return nil
+		}
+	}
+	allowed := make([]string, 0, len(atom.enum))
+	for _, elem := range atom.enum {
+		allowed = append(allowed, fmt.Sprintf("%v", elem))
+	}
+	return fmt.Errorf("%v is not one of the allowed values [%s]", value, strings.Join(allowed, ", "))
+}
+
+func atomEqual(schemaValue, value interface{}) bool {
+	if number, ok := schemaValue.(float64); ok {
+		real, ok := toReal(value)
+		return ok && real == number
+	}
+	return schemaValue == value
+}
+
+func toInteger(value interface{}) (int64, bool) {
+	switch value.(type) {
+	case int:
+		return int64(value.(int)), true
+	case int8:
+		return int64(value.(int8)), true
+	case int16:
+		return int64(value.(int16)), true
+	case int32:
+		return int64(value.(int32)), true
+	case int64:
+		return value.(int64), true
+	case uint:
+		return toInteger(uint64(value.(uint)))
+	case uint8:
+		return int64(value.(uint8)), true
+	case uint16:
+		return int64(value.(uint16)), true
+	case uint32:
+		return int64(value.(uint32)), true
+	case uint64:
+		if value.(uint64) > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value.(uint64)), true
+	case float64:
+		real := value.(float64)
+		if real != math.Trunc(real) {
+			return 0, false
+		}
+		return int64(real), true
+	}
+	return 0, false
+}
+
+func toReal(value interface{}) (float64, bool) {
+	if real, ok := value.(float64); ok {
+		return real, true
+	}
+	integer, ok := toInteger(value)
+	return float64(integer), ok
+}
+
+func toGoSet(value interface{}) []interface{} {
+	switch value.(type) {
+	case libovsdb.OvsSet:
+		return value.(libovsdb.OvsSet).GoSet
+	case *libovsdb.OvsSet:
+		return value.(*libovsdb.OvsSet).GoSet
+	}
+	return nil
+}
+
+func toGoMap(value interface{}) map[interface{}]interface{} {
+	switch value.(type) {
+	case libovsdb.OvsMap:
+		return value.(libovsdb.OvsMap).GoMap
+	case *libovsdb.OvsMap:
+		return value.(*libovsdb.OvsMap).GoMap
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a row in a stable order so that the same
+// invalid row always reports the same column
+func sortedKeys(row map[string]interface{}) []string {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package goovs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const testSchema = `{
+	"name": "Open_vSwitch",
+	"version": "7.12.1",
+	"tables": {
+		"Port": {
+			"columns": {
+				"name": {"type": "string"},
+				"tag": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 4095}, "min": 0, "max": 1}},
+				"vlan_mode": {"type": {"key": {"type": "string", "enum": ["set", ["access", "native-tagged", "native-untagged", "trunk"]]}, "min": 0, "max": 1}},
+				"interfaces": {"type": {"key": {"type": "uuid", "refTable": "Interface"}, "min": 1, "max": "unlimited"}}
+			}
+		},
+		"Interface": {
+			"columns": {
+				"name": {"type": "string"},
+				"type": {"type": "string"},
+				"options": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+			}
+		}
+	}
+}`
+
+func loadTestSchema(t *testing.T) *ovsSchema {
+	var dbSchema libovsdb.DatabaseSchema
+	if err := json.Unmarshal([]byte(testSchema), &dbSchema); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := newOvsSchema(&dbSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestNewOvsSchema(t *testing.T) {
+	schema := loadTestSchema(t)
+	tag := schema.tables["Port"]["tag"]
+	if tag.min != 0 || tag.max != 1 || tag.key.maxInteger != 4095 {
+		t.Fatalf("The tag column type %+v is incorrect", tag)
+	}
+	if !schema.tables["Interface"]["options"].isMap() {
+		t.Fatal("The options column should be a map")
+	}
+	if _, ok := schema.tables["Port"]["_uuid"]; !ok {
+		t.Fatal("The implicit _uuid column is missing")
+	}
+}
+
+func TestValidateOperation(t *testing.T) {
+	schema := loadTestSchema(t)
+	options, _ := libovsdb.NewOvsMap(map[string]string{"peer": "p2"})
+	valid := []libovsdb.Operation{
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"name": "p1", "tag": 10, "interfaces": libovsdb.UUID{GoUUID: "gointerface"}}},
+		{Op: insertOperation, Table: interfaceTableName, Row: map[string]interface{}{"name": "p1", "type": "patch", "options": options}},
+		{Op: updateOperation, Table: portTableName, Row: map[string]interface{}{"vlan_mode": "trunk"}, Where: []interface{}{libovsdb.NewCondition("_uuid", "==", []string{"uuid", "abcde12345"})}},
+		{Op: updateOperation, Table: portTableName, Row: map[string]interface{}{"tag": uint16(10)}, Where: []interface{}{libovsdb.NewCondition("tag", "<", uint64(20))}},
+		{Op: selectOperation, Table: portTableName, Where: []interface{}{libovsdb.NewCondition("interfaces", "includes", libovsdb.UUID{GoUUID: "abcde12345"})}},
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"tag": int8(1)}},
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"tag": uint(1)}},
+	}
+	for _, operation := range valid {
+		if err := schema.validateOperation(operation); err != nil {
+			t.Fatalf("The operation %+v should be valid: %s", operation, err.Error())
+		}
+	}
+
+	invalid := []libovsdb.Operation{
+		{Op: insertOperation, Table: "Unknown", Row: map[string]interface{}{"name": "p1"}},
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"nmae": "p1"}},
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"tag": 4096}},
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"vlan_mode": "dot1q"}},
+		{Op: insertOperation, Table: interfaceTableName, Row: map[string]interface{}{"options": "{peer=p2}"}},
+		{Op: deleteOperation, Table: portTableName, Where: []interface{}{libovsdb.NewCondition("nmae", "==", "p1")}},
+		{Op: deleteOperation, Table: portTableName, Where: []interface{}{libovsdb.NewCondition("name", "==", 1)}},
+		{Op: deleteOperation, Table: portTableName, Where: []interface{}{libovsdb.NewCondition("tag", "==", "10")}},
+		{Op: selectOperation, Table: portTableName, Where: []interface{}{libovsdb.NewCondition("name", "<", "p1")}},
+		{Op: insertOperation, Table: portTableName, Row: map[string]interface{}{"tag": uint64(1 << 63)}},
+		{Op: mutateOperation, Table: portTableName, Mutations: []interface{}{libovsdb.NewMutation("interfaces", insertOperation, "p1")}},
+	}
+	for _, operation := range invalid {
+		if err := schema.validateOperation(operation); err == nil {
+			t.Fatalf("The operation %+v should be invalid", operation)
+		}
+	}
+}
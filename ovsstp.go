@@ -0,0 +1,205 @@
+package goovs
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// STPSpec holds the bridge wide STP settings, the times are in seconds.
+// SetBridgeSTP removes a nil priority or a zero time from the bridge
+type STPSpec struct {
+	Priority     *int
+	HelloTime    int
+	MaxAge       int
+	ForwardDelay int
+}
+
+// RSTPSpec holds the bridge wide RSTP settings in seconds. Each nil or zero
+// setting goes back to the value ovs-vswitchd picks
+type RSTPSpec struct {
+	Priority          *int
+	AgeingTime        int
+	MaxAge            int
+	ForwardDelay      int
+	TransmitHoldCount int
+}
+
+// PortSTPSpec holds the per port STP or RSTP settings. The path cost is
+// computed from the link speed when zero, the priority is unset when nil
+type PortSTPSpec struct {
+	Priority *int
+	PathCost int
+}
+
+// BridgeSTPStatus is the STP status of a bridge
+type BridgeSTPStatus struct {
+	BridgeID       string `json:"stp_bridge_id"`
+	DesignatedRoot string `json:"stp_designated_root"`
+	RootPathCost   int    `json:"stp_root_path_cost"`
+}
+
+// BridgeRSTPStatus is the RSTP status of a bridge
+type BridgeRSTPStatus struct {
+	BridgeID         string `json:"rstp_bridge_id"`
+	RootID           string `json:"rstp_root_id"`
+	RootPathCost     int    `json:"rstp_root_path_cost"`
+	DesignatedID     string `json:"rstp_designated_id"`
+	DesignatedPortID string `json:"rstp_designated_port_id"`
+	BridgePortID     string `json:"rstp_bridge_port_id"`
+}
+
+// PortSTPStatus is the STP status of a port
+type PortSTPStatus struct {
+	PortID     string `json:"stp_port_id"`
+	State      string `json:"stp_state"`
+	Role       string `json:"stp_role"`
+	SecInState int    `json:"stp_sec_in_state"`
+}
+
+// PortRSTPStatus is the RSTP status of a port
+type PortRSTPStatus struct {
+	PortID             string `json:"rstp_port_id"`
+	Role               string `json:"rstp_port_role"`
+	State              string `json:"rstp_port_state"`
+	DesignatedBridgeID string `json:"rstp_designated_bridge_id"`
+	DesignatedPortID   string `json:"rstp_designated_port_id"`
+	DesignatedPathCost int    `json:"rstp_designated_path_cost"`
+}
+
+func (status *BridgeSTPStatus) readFromMap(values map[string]string) {
+	status.BridgeID = values["stp_bridge_id"]
+	status.DesignatedRoot = values["stp_designated_root"]
+	status.RootPathCost, _ = strconv.Atoi(values["stp_root_path_cost"])
+}
+
+func (status *BridgeRSTPStatus) readFromMap(values map[string]string) {
+	status.BridgeID = values["rstp_bridge_id"]
+	status.RootID = values["rstp_root_id"]
+	status.RootPathCost, _ = strconv.Atoi(values["rstp_root_path_cost"])
+	status.DesignatedID = values["rstp_designated_id"]
+	status.DesignatedPortID = values["rstp_designated_port_id"]
+	status.BridgePortID = values["rstp_bridge_port_id"]
+}
+
+func (status *PortSTPStatus) readFromMap(values map[string]string) {
+	status.PortID = values["stp_port_id"]
+	status.State = values["stp_state"]
+	status.Role = values["stp_role"]
+	status.SecInState, _ = strconv.Atoi(values["stp_sec_in_state"])
+}
+
+func (status *PortRSTPStatus) readFromMap(values map[string]string) {
+	status.PortID = values["rstp_port_id"]
+	status.Role = values["rstp_port_role"]
+	status.State = values["rstp_port_state"]
+	status.DesignatedBridgeID = values["rstp_designated_bridge_id"]
+	status.DesignatedPortID = values["rstp_designated_port_id"]
+	status.DesignatedPathCost, _ = strconv.Atoi(values["rstp_designated_path_cost"])
+}
+
+// SetBridgeSTP enables or disables STP on a bridge. Enabling STP disables
+// RSTP since both are mutually exclusive
+func (client *ovsClient) SetBridgeSTP(brname string, enable bool, spec STPSpec) error {
+	config, err := newIntConfig([]intSetting{
+		{"stp-priority", spec.Priority, 0, 65535},
+		{"stp-hello-time", nonZeroInt(spec.HelloTime), 1, 10},
+		{"stp-max-age", nonZeroInt(spec.MaxAge), 6, 40},
+		{"stp-forward-delay", nonZeroInt(spec.ForwardDelay), 4, 30},
+	})
+	if err != nil {
+		return err
+	}
+	bridge := make(map[string]interface{})
+	bridge["stp_enable"] = enable
+	if enable {
+		bridge["rstp_enable"] = false
+	}
+	return client.updateBridgeConfig(brname, bridge, config, "set bridge stp")
+}
+
+// SetBridgeRSTP enables or disables RSTP on a bridge. Enabling RSTP
+// disables STP since both are mutually exclusive
+func (client *ovsClient) SetBridgeRSTP(brname string, enable bool, spec RSTPSpec) error {
+	if spec.Priority != nil && *spec.Priority%4096 != 0 {
+		return fmt.Errorf("The rstp-priority value %d is not a multiple of 4096", *spec.Priority)
+	}
+	config, err := newIntConfig([]intSetting{
+		{"rstp-priority", spec.Priority, 0, 61440},
+		{"rstp-ageing-time", nonZeroInt(spec.AgeingTime), 10, 1000000},
+		{"rstp-max-age", nonZeroInt(spec.MaxAge), 6, 40},
+		{"rstp-forward-delay", nonZeroInt(spec.ForwardDelay), 4, 30},
+		{"rstp-transmit-hold-count", nonZeroInt(spec.TransmitHoldCount), 1, 10},
+	})
+	if err != nil {
+		return err
+	}
+	bridge := make(map[string]interface{})
+	bridge["rstp_enable"] = enable
+	if enable {
+		bridge["stp_enable"] = false
+	}
+	return client.updateBridgeConfig(brname, bridge, config, "set bridge rstp")
+}
+
+// SetPortSTP sets the STP priority and path cost of a port
+func (client *ovsClient) SetPortSTP(portname string, spec PortSTPSpec) error {
+	config, err := newIntConfig([]intSetting{
+		{"stp-port-priority", spec.Priority, 0, 255},
+		{"stp-path-cost", nonZeroInt(spec.PathCost), 0, 65535},
+	})
+	if err != nil {
+		return err
+	}
+	return client.updatePortOtherConfig(portname, config, "set port stp")
+}
+
+// SetPortRSTP sets the RSTP priority and path cost of a port
+func (client *ovsClient) SetPortRSTP(portname string, spec PortSTPSpec) error {
+	if spec.Priority != nil && *spec.Priority%16 != 0 {
+		return fmt.Errorf("The rstp-port-priority value %d is not a multiple of 16", *spec.Priority)
+	}
+	config, err := newIntConfig([]intSetting{
+		{"rstp-port-priority", spec.Priority, 0, 240},
+		{"rstp-port-path-cost", nonZeroInt(spec.PathCost), 1, 200000000},
+	})
+	if err != nil {
+		return err
+	}
+	return client.updatePortOtherConfig(portname, config, "set port rstp")
+}
+
+// GetBridgeSTPStatus returns the STP status of a bridge
+func (client *ovsClient) GetBridgeSTPStatus(brname string) (BridgeSTPStatus, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return BridgeSTPStatus{}, err
+	}
+	return bridge.STPStatus, nil
+}
+
+// GetBridgeRSTPStatus returns the RSTP status of a bridge
+func (client *ovsClient) GetBridgeRSTPStatus(brname string) (BridgeRSTPStatus, error) {
+	bridge, err := client.getBridgeByName(brname)
+	if err != nil {
+		return BridgeRSTPStatus{}, err
+	}
+	return bridge.RSTPStatus, nil
+}
+
+// GetPortSTPStatus returns the STP status of a port
+func (client *ovsClient) GetPortSTPStatus(portname string) (PortSTPStatus, error) {
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return PortSTPStatus{}, err
+	}
+	return port.STPStatus, nil
+}
+
+// GetPortRSTPStatus returns the RSTP status of a port
+func (client *ovsClient) GetPortRSTPStatus(portname string) (PortRSTPStatus, error) {
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return PortRSTPStatus{}, err
+	}
+	return port.RSTPStatus, nil
+}
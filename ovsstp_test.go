@@ -0,0 +1,39 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestSTPStatusReadFromDBRow(t *testing.T) {
+	status, _ := libovsdb.NewOvsMap(map[string]string{"stp_state": "forwarding", "stp_role": "root", "stp_sec_in_state": "12"})
+	rstpStatus, _ := libovsdb.NewOvsMap(map[string]string{"rstp_port_role": "Designated", "rstp_port_state": "Forwarding", "rstp_designated_path_cost": "20000"})
+	port := &OvsPort{UUID: "abcde12345"}
+	row := &libovsdb.Row{Fields: map[string]interface{}{
+		"name":        "p1",
+		"status":      *status,
+		"rstp_status": *rstpStatus,
+	}}
+	if err := port.ReadFromDBRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if port.STPStatus.State != "forwarding" || port.STPStatus.Role != "root" || port.STPStatus.SecInState != 12 {
+		t.Fatalf("The port stp status %+v is incorrect", port.STPStatus)
+	}
+	if port.RSTPStatus.Role != "Designated" || port.RSTPStatus.DesignatedPathCost != 20000 {
+		t.Fatalf("The port rstp status %+v is incorrect", port.RSTPStatus)
+	}
+}
+
+func TestSetBridgeSTP(t *testing.T) {
+	// TODO
+}
+
+func TestSetBridgeRSTP(t *testing.T) {
+	// TODO
+}
+
+func TestSetPortSTP(t *testing.T) {
+	// TODO
+}
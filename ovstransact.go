@@ -0,0 +1,101 @@
+package goovs
+
+import (
+	"sync"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+// OvsTransactor sends raw libovsdb operations. It is kept out of OvsClient
+// so that only the callers building their own operations depend on
+// libovsdb, they get it with a type assertion on the client returned by
+// GetOVSClient
+type OvsTransactor interface {
+	TransactAsync(operations []libovsdb.Operation, action string) *TransactFuture
+	NewTransactPipeline() *TransactPipeline
+}
+
+// TransactFuture is the pending result of an asynchronous transaction
+type TransactFuture struct {
+	done chan struct{}
+	err  error
+	// firstErr is the first error of the pipeline up to this transaction
+	firstErr error
+}
+
+// Done returns a channel which is closed once the result is available
+func (future *TransactFuture) Done() <-chan struct{} {
+	return future.done
+}
+
+// Wait blocks until the transaction completes and returns its error
+func (future *TransactFuture) Wait() error {
+	<-future.done
+	return future.err
+}
+
+// TransactPipeline sends transactions without waiting for the previous ones
+// to complete, while their results are delivered in the submission order.
+// ovsdb-server may receive the transactions in any order, so only
+// independent transactions should be pipelined
+type TransactPipeline struct {
+	client *ovsClient
+	mutex  sync.Mutex
+	last   *TransactFuture
+}
+
+// TransactAsync sends the operations as a single transaction and returns
+// immediately. The operations must not be modified until the future is done
+func (client *ovsClient) TransactAsync(operations []libovsdb.Operation, action string) *TransactFuture {
+	return newTransactFuture(func() error {
+		return client.transact(operations, action)
+	}, nil)
+}
+
+// NewTransactPipeline creates a pipeline sharing the client connection
+func (client *ovsClient) NewTransactPipeline() *TransactPipeline {
+	return &TransactPipeline{client: client}
+}
+
+// Transact sends the operations as a single transaction, its result becomes
+// available after the results of the transactions submitted before it
+func (pipeline *TransactPipeline) Transact(operations []libovsdb.Operation, action string) *TransactFuture {
+	pipeline.mutex.Lock()
+	defer pipeline.mutex.Unlock()
+	future := newTransactFuture(func() error {
+		return pipeline.client.transact(operations, action)
+	}, pipeline.last)
+	pipeline.last = future
+	return future
+}
+
+// Wait blocks until all the transactions submitted so far are completed and
+// returns the first error encountered
+func (pipeline *TransactPipeline) Wait() error {
+	pipeline.mutex.Lock()
+	last := pipeline.last
+	pipeline.mutex.Unlock()
+	if last == nil {
+		return nil
+	}
+	<-last.done
+	return last.firstErr
+}
+
+// newTransactFuture runs the transaction in the background. If previous is
+// given, the result is only published once previous is done
+func newTransactFuture(run func() error, previous *TransactFuture) *TransactFuture {
+	future := &TransactFuture{done: make(chan struct{})}
+	go func() {
+		future.err = run()
+		future.firstErr = future.err
+		if previous != nil {
+			<-previous.done
+			if previous.firstErr != nil {
+				future.firstErr = previous.firstErr
+			}
+		}
+		close(future.done)
+	}()
+	return future
+}
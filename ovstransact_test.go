@@ -0,0 +1,44 @@
+package goovs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNewTransactFuture(t *testing.T) {
+	slow := newTransactFuture(func() error {
+		time.Sleep(50 * time.Millisecond)
+		return fmt.Errorf("slow transaction failed")
+	}, nil)
+	fast := newTransactFuture(func() error {
+		return nil
+	}, slow)
+
+	select {
+	case <-fast.Done():
+		t.Fatal("The result should not be delivered before the previous one")
+	case <-slow.Done():
+	}
+	if err := fast.Wait(); err != nil {
+		t.Fatalf("The fast transaction should succeed, got %s", err.Error())
+	}
+	if fast.firstErr == nil {
+		t.Fatal("The error of the previous transaction should be kept for the pipeline")
+	}
+}
+
+func TestOvsTransactor(t *testing.T) {
+	var client OvsClient = &ovsClient{}
+	if _, ok := client.(OvsTransactor); !ok {
+		t.Fatal("The client should implement OvsTransactor")
+	}
+}
+
+func TestTransactAsync(t *testing.T) {
+	// TODO
+}
+
+func TestTransactPipeline(t *testing.T) {
+	// TODO
+}
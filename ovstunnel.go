@@ -0,0 +1,305 @@
+package goovs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+const (
+	// TunnelTypeVXLAN is a VXLAN tunnel, the key is the 24 bit VNI
+	TunnelTypeVXLAN = "vxlan"
+	// TunnelTypeGeneve is a Geneve tunnel, the key is the 24 bit VNI
+	TunnelTypeGeneve = "geneve"
+	// TunnelTypeGRE is a GRE tunnel, the key is 32 bit
+	TunnelTypeGRE = "gre"
+	// TunnelTypeIP6GRE is a GRE tunnel over IPv6
+	TunnelTypeIP6GRE = "ip6gre"
+	// TunnelTypeERSPAN is an ERSPAN tunnel, the key is the 10 bit session id
+	TunnelTypeERSPAN = "erspan"
+	// TunnelTypeIP6ERSPAN is an ERSPAN tunnel over IPv6
+	TunnelTypeIP6ERSPAN = "ip6erspan"
+	// TunnelTypeSTT is an STT tunnel, the key is 64 bit
+	TunnelTypeSTT = "stt"
+)
+
+// tunnelFlowValue lets the OpenFlow flows set an option, e.g. remote_ip=flow
+const tunnelFlowValue = "flow"
+
+// tunnelKeyBits is the size of the key of each tunnel type
+var tunnelKeyBits = map[string]uint{
+	TunnelTypeVXLAN:     24,
+	TunnelTypeGeneve:    24,
+	TunnelTypeGRE:       32,
+	TunnelTypeIP6GRE:    32,
+	TunnelTypeERSPAN:    10,
+	TunnelTypeIP6ERSPAN: 10,
+	TunnelTypeSTT:       64,
+}
+
+// tunnelOptionKeys are the interface options set by the TunnelSpec fields
+var tunnelOptionKeys = map[string]bool{
+	"remote_ip":   true,
+	"local_ip":    true,
+	"key":         true,
+	"in_key":      true,
+	"out_key":     true,
+	"dst_port":    true,
+	"tos":         true,
+	"ttl":         true,
+	"csum":        true,
+	"df_default":  true,
+	"erspan_ver":  true,
+	"erspan_idx":  true,
+	"erspan_dir":  true,
+	"erspan_hwid": true,
+}
+
+// TunnelSpec describes a tunnel interface. RemoteIP, LocalIP and the keys
+// accept "flow" to let the flows set them, TOS and TTL accept "inherit".
+// Empty and zero values leave the ovs defaults, Options holds any other
+// interface option and can't hold the options of the fields
+type TunnelSpec struct {
+	Type      string
+	RemoteIP  string
+	LocalIP   string
+	Key       string
+	InKey     string
+	OutKey    string
+	DstPort   int
+	TOS       string
+	TTL       string
+	Csum      *bool
+	DFDefault *bool
+	// ERSPANVersion is 1 or 2, ERSPANIndex is used by version 1 while
+	// ERSPANDir and ERSPANHwID are used by version 2
+	ERSPANVersion int
+	ERSPANIndex   int
+	ERSPANDir     int
+	ERSPANHwID    int
+	Options       map[string]string
+}
+
+// CreateTunnelPort creates a port with a tunnel interface
+func (client *ovsClient) CreateTunnelPort(brname, portname string, spec TunnelSpec) error {
+	options, err := spec.options()
+	if err != nil {
+		return err
+	}
+	return client.CreatePorts(brname, []PortSpec{{
+		Name:    portname,
+		Type:    spec.Type,
+		Options: options,
+	}})
+}
+
+func isTunnelType(intftype string) bool {
+	_, ok := tunnelKeyBits[intftype]
+	return ok
+}
+
+// options validates the spec for its tunnel type and returns the interface
+// options
+func (spec *TunnelSpec) options() (map[string]string, error) {
+	keyBits, ok := tunnelKeyBits[spec.Type]
+	if !ok {
+		return nil, fmt.Errorf("The tunnel type %s is not supported", spec.Type)
+	}
+	ipv6Only := spec.Type == TunnelTypeIP6GRE || spec.Type == TunnelTypeIP6ERSPAN
+	erspan := spec.Type == TunnelTypeERSPAN || spec.Type == TunnelTypeIP6ERSPAN
+
+	options := make(map[string]string)
+	for key, value := range spec.Options {
+		if tunnelOptionKeys[key] {
+			return nil, fmt.Errorf("The %s option of the %s tunnel should be set by its TunnelSpec field", key, spec.Type)
+		}
+		options[key] = value
+	}
+	if spec.RemoteIP == "" {
+		return nil, fmt.Errorf("The %s tunnel has no remote_ip", spec.Type)
+	}
+	for name, ip := range map[string]string{"remote_ip": spec.RemoteIP, "local_ip": spec.LocalIP} {
+		if ip == "" {
+			continue
+		}
+		if err := validateTunnelIP(name, ip, ipv6Only); err != nil {
+			return nil, err
+		}
+		options[name] = ip
+	}
+	if spec.LocalIP != "" && spec.LocalIP != tunnelFlowValue && spec.RemoteIP != tunnelFlowValue &&
+		(net.ParseIP(spec.RemoteIP).To4() == nil) != (net.ParseIP(spec.LocalIP).To4() == nil) {
+		return nil, fmt.Errorf("The remote_ip %s and the local_ip %s are not of the same address family", spec.RemoteIP, spec.LocalIP)
+	}
+
+	if spec.Key != "" && (spec.InKey != "" || spec.OutKey != "") {
+		return nil, fmt.Errorf("The key of the %s tunnel can't be set along with in_key or out_key", spec.Type)
+	}
+	for name, key := range map[string]string{"key": spec.Key, "in_key": spec.InKey, "out_key": spec.OutKey} {
+		if key == "" {
+			continue
+		}
+		if err := validateTunnelKey(name, key, keyBits); err != nil {
+			return nil, err
+		}
+		options[name] = key
+	}
+
+	if spec.DstPort != 0 {
+		if spec.Type == TunnelTypeGRE || spec.Type == TunnelTypeIP6GRE || erspan {
+			return nil, fmt.Errorf("The %s tunnel doesn't use a dst_port", spec.Type)
+		}
+		if spec.DstPort < 1 || spec.DstPort > 65535 {
+			return nil, fmt.Errorf("The dst_port value %d is invalid", spec.DstPort)
+		}
+		options["dst_port"] = strconv.Itoa(spec.DstPort)
+	}
+	if spec.TOS != "" {
+		if err := validateInheritOrInt("tos", spec.TOS, 0, 255); err != nil {
+			return nil, err
+		}
+		options["tos"] = spec.TOS
+	}
+	if spec.TTL != "" {
+		if err := validateInheritOrInt("ttl", spec.TTL, 1, 255); err != nil {
+			return nil, err
+		}
+		options["ttl"] = spec.TTL
+	}
+	if spec.Csum != nil {
+		if spec.Type == TunnelTypeSTT || erspan {
+			return nil, fmt.Errorf("The %s tunnel doesn't support csum", spec.Type)
+		}
+		options["csum"] = strconv.FormatBool(*spec.Csum)
+	}
+	if spec.DFDefault != nil {
+		options["df_default"] = strconv.FormatBool(*spec.DFDefault)
+	}
+
+	if !erspan {
+		if spec.ERSPANVersion != 0 || spec.ERSPANIndex != 0 || spec.ERSPANDir != 0 || spec.ERSPANHwID != 0 {
+			return nil, fmt.Errorf("The ERSPAN options are not allowed on the %s tunnel", spec.Type)
+		}
+		return options, nil
+	}
+	switch spec.ERSPANVersion {
+	case 0, 1:
+		if spec.ERSPANDir != 0 || spec.ERSPANHwID != 0 {
+			return nil, fmt.Errorf("The erspan_dir and erspan_hwid options require the ERSPAN version 2")
+		}
+		if spec.ERSPANIndex < 0 || spec.ERSPANIndex > 0xfffff {
+			return nil, fmt.Errorf("The erspan_idx value %d is invalid", spec.ERSPANIndex)
+		} else if spec.ERSPANIndex != 0 {
+			options["erspan_idx"] = strconv.Itoa(spec.ERSPANIndex)
+		}
+	case 2:
+		if spec.ERSPANIndex != 0 {
+			return nil, fmt.Errorf("The erspan_idx option requires the ERSPAN version 1")
+		}
+		if spec.ERSPANDir < 0 || spec.ERSPANDir > 1 {
+			return nil, fmt.Errorf("The erspan_dir value %d is invalid", spec.ERSPANDir)
+		}
+		if spec.ERSPANHwID < 0 || spec.ERSPANHwID > 0x3f {
+			return nil, fmt.Errorf("The erspan_hwid value %d is invalid", spec.ERSPANHwID)
+		}
+		if spec.ERSPANDir != 0 {
+			options["erspan_dir"] = strconv.Itoa(spec.ERSPANDir)
+		}
+		if spec.ERSPANHwID != 0 {
+			options["erspan_hwid"] = strconv.Itoa(spec.ERSPANHwID)
+		}
+	default:
+		return nil, fmt.Errorf("The erspan_ver value %d is invalid, it should be 1 or 2", spec.ERSPANVersion)
+	}
+	if spec.ERSPANVersion != 0 {
+		options["erspan_ver"] = strconv.Itoa(spec.ERSPANVersion)
+	}
+	return options, nil
+}
+
+// newTunnelSpecFromOptions builds the TunnelSpec of the interface options of
+// a tunnel, so that the options of a PortSpec are validated like a TunnelSpec
+func newTunnelSpecFromOptions(tunnelType string, options map[string]string) (TunnelSpec, error) {
+	spec := TunnelSpec{Type: tunnelType, Options: make(map[string]string)}
+	for key, value := range options {
+		var err error
+		switch key {
+		case "remote_ip":
+			spec.RemoteIP = value
+		case "local_ip":
+			spec.LocalIP = value
+		case "key":
+			spec.Key = value
+		case "in_key":
+			spec.InKey = value
+		case "out_key":
+			spec.OutKey = value
+		case "tos":
+			spec.TOS = value
+		case "ttl":
+			spec.TTL = value
+		case "dst_port":
+			if spec.DstPort, err = strconv.Atoi(value); err == nil && spec.DstPort == 0 {
+				err = fmt.Errorf("The dst_port can't be 0")
+			}
+		case "csum":
+			var csum bool
+			csum, err = strconv.ParseBool(value)
+			spec.Csum = &csum
+		case "df_default":
+			var dfDefault bool
+			dfDefault, err = strconv.ParseBool(value)
+			spec.DFDefault = &dfDefault
+		case "erspan_ver":
+			spec.ERSPANVersion, err = strconv.Atoi(value)
+		case "erspan_idx":
+			spec.ERSPANIndex, err = strconv.Atoi(value)
+		case "erspan_dir":
+			spec.ERSPANDir, err = strconv.Atoi(value)
+		case "erspan_hwid":
+			spec.ERSPANHwID, err = strconv.Atoi(value)
+		default:
+			spec.Options[key] = value
+		}
+		if err != nil {
+			return TunnelSpec{}, fmt.Errorf("The %s value %s of the %s tunnel is invalid", key, value, tunnelType)
+		}
+	}
+	return spec, nil
+}
+
+func validateTunnelIP(name, ip string, ipv6Only bool) error {
+	if ip == tunnelFlowValue {
+		return nil
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("The %s value %s is not an IP address", name, ip)
+	}
+	if ipv6Only && parsed.To4() != nil {
+		return fmt.Errorf("The %s value %s is not an IPv6 address", name, ip)
+	}
+	return nil
+}
+
+func validateTunnelKey(name, key string, bits uint) error {
+	if key == tunnelFlowValue {
+		return nil
+	}
+	value, err := strconv.ParseUint(key, 0, 64)
+	if err != nil || (bits < 64 && value > (uint64(1)<<bits)-1) {
+		return fmt.Errorf("The %s value %s is invalid, it should be a %d bit number or %s", name, key, bits, tunnelFlowValue)
+	}
+	return nil
+}
+
+func validateInheritOrInt(name, value string, min, max int) error {
+	if value == "inherit" {
+		return nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return fmt.Errorf("The %s value %s is invalid, it should be inherit or within [%d, %d]", name, value, min, max)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package goovs
+
+import (
+	"testing"
+)
+
+func TestTunnelSpecOptions(t *testing.T) {
+	csum := true
+	spec := TunnelSpec{Type: TunnelTypeVXLAN, RemoteIP: "192.0.2.1", Key: "5000", DstPort: 4789, TTL: "inherit", Csum: &csum}
+	options, err := spec.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options["remote_ip"] != "192.0.2.1" || options["key"] != "5000" || options["dst_port"] != "4789" || options["csum"] != "true" {
+		t.Fatalf("The tunnel options %v are incorrect", options)
+	}
+	erspan := TunnelSpec{Type: TunnelTypeERSPAN, RemoteIP: "192.0.2.1", Key: "1", ERSPANVersion: 2, ERSPANDir: 1, ERSPANHwID: 4}
+	if options, err = erspan.options(); err != nil {
+		t.Fatal(err)
+	}
+	if options["erspan_ver"] != "2" || options["erspan_dir"] != "1" || options["erspan_hwid"] != "4" {
+		t.Fatalf("The erspan options %v are incorrect", options)
+	}
+	invalid := []TunnelSpec{
+		{Type: "lisp", RemoteIP: "192.0.2.1"},
+		{Type: TunnelTypeVXLAN},
+		{Type: TunnelTypeVXLAN, RemoteIP: "host"},
+		{Type: TunnelTypeVXLAN, RemoteIP: "192.0.2.1", Key: "16777216"},
+		{Type: TunnelTypeVXLAN, RemoteIP: "192.0.2.1", Key: "1", InKey: "2"},
+		{Type: TunnelTypeGRE, RemoteIP: "192.0.2.1", DstPort: 4789},
+		{Type: TunnelTypeIP6GRE, RemoteIP: "192.0.2.1"},
+		{Type: TunnelTypeGeneve, RemoteIP: "192.0.2.1", TTL: "0"},
+		{Type: TunnelTypeERSPAN, RemoteIP: "192.0.2.1", Key: "1024"},
+		{Type: TunnelTypeERSPAN, RemoteIP: "192.0.2.1", ERSPANVersion: 1, ERSPANHwID: 1},
+		{Type: TunnelTypeGRE, RemoteIP: "192.0.2.1", ERSPANVersion: 1},
+		{Type: TunnelTypeVXLAN, RemoteIP: "192.0.2.1", LocalIP: "2001:db8::1"},
+		{Type: TunnelTypeGeneve, RemoteIP: "2001:db8::2", LocalIP: "192.0.2.1"},
+		{Type: TunnelTypeVXLAN, RemoteIP: "192.0.2.1", Options: map[string]string{"key": "16777216"}},
+		{Type: TunnelTypeERSPAN, RemoteIP: "192.0.2.1", Options: map[string]string{"erspan_ver": "3"}},
+	}
+	for _, spec := range invalid {
+		if _, err = spec.options(); err == nil {
+			t.Fatalf("The tunnel spec %+v should be invalid", spec)
+		}
+	}
+	flow := TunnelSpec{Type: TunnelTypeGeneve, RemoteIP: "flow", LocalIP: "2001:db8::1", Key: "flow"}
+	if _, err = flow.options(); err != nil {
+		t.Fatal(err)
+	}
+	ipv6 := TunnelSpec{Type: TunnelTypeVXLAN, RemoteIP: "2001:db8::2", LocalIP: "2001:db8::1"}
+	if _, err = ipv6.options(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewTunnelSpecFromOptions(t *testing.T) {
+	options := map[string]string{"remote_ip": "192.0.2.1", "key": "5000", "dst_port": "4789", "csum": "true", "tos": "inherit", "packet_type": "legacy_l2"}
+	spec, err := newTunnelSpecFromOptions(TunnelTypeVXLAN, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.RemoteIP != "192.0.2.1" || spec.Key != "5000" || spec.DstPort != 4789 || spec.Csum == nil || !*spec.Csum || len(spec.Options) != 1 {
+		t.Fatalf("The tunnel spec %+v is incorrect", spec)
+	}
+	built, err := spec.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(built) != len(options) || built["dst_port"] != "4789" || built["tos"] != "inherit" {
+		t.Fatalf("The options %v should match %v", built, options)
+	}
+	for _, invalid := range []map[string]string{
+		{"remote_ip": "192.0.2.1", "dst_port": "vxlan"},
+		{"remote_ip": "192.0.2.1", "dst_port": "0"},
+		{"remote_ip": "192.0.2.1", "csum": "maybe"},
+	} {
+		if _, err = newTunnelSpecFromOptions(TunnelTypeVXLAN, invalid); err == nil {
+			t.Fatalf("The tunnel options %v should be invalid", invalid)
+		}
+	}
+}
+
+func TestCreateTunnelPort(t *testing.T) {
+	// TODO
+}
@@ -0,0 +1,184 @@
+package goovs
+
+import (
+	"fmt"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+const (
+	// VlanModeAccess carries the untagged traffic of the port tag
+	VlanModeAccess = "access"
+	// VlanModeTrunk carries the tagged traffic of the trunk VLANs
+	VlanModeTrunk = "trunk"
+	// VlanModeNativeTagged is a trunk where the native VLAN given by the tag
+	// is tagged
+	VlanModeNativeTagged = "native-tagged"
+	// VlanModeNativeUntagged is a trunk where the native VLAN given by the
+	// tag is untagged
+	VlanModeNativeUntagged = "native-untagged"
+	// VlanModeDot1qTunnel pushes the tag as the outer VLAN of the customer
+	// VLANs, i.e. QinQ
+	VlanModeDot1qTunnel = "dot1q-tunnel"
+)
+
+const (
+	// QinQEthType8021ad is the 0x88a8 outer ethertype, the ovs default
+	QinQEthType8021ad = "802.1ad"
+	// QinQEthType8021q is the 0x8100 outer ethertype
+	QinQEthType8021q = "802.1q"
+)
+
+// VlanSpec describes the VLAN settings of a port. An empty mode lets ovs pick
+// access if a tag is set and trunk otherwise. The tag is set if HasTag is true
+// or Tag is not 0, so that HasTag is only needed for the vlan 0. CVLANs and
+// QinQEthType are only used by the dot1q-tunnel mode
+type VlanSpec struct {
+	Mode        string
+	Tag         int
+	HasTag      bool
+	Trunks      []int
+	CVLANs      []int
+	QinQEthType string
+}
+
+// CreateTrunkPort creates a system port carrying the tagged traffic of the
+// trunk VLANs, all VLANs are carried if trunks is empty
+func (client *ovsClient) CreateTrunkPort(brname, portname string, trunks []int) error {
+	return client.CreatePorts(brname, []PortSpec{{
+		Name:     portname,
+		VlanMode: VlanModeTrunk,
+		Trunks:   trunks,
+	}})
+}
+
+// UpdatePortVlan replaces the VLAN settings of a port
+func (client *ovsClient) UpdatePortVlan(portname string, spec VlanSpec) error {
+	vlanColumns, err := spec.portColumns()
+	if err != nil {
+		return err
+	}
+	portUpdateLock.Lock()
+	defer portUpdateLock.Unlock()
+	if _, err = client.getPortByName(portname); err != nil {
+		return err
+	}
+
+	// The unset columns are cleared so that the spec replaces the settings
+	emptyInts, _ := libovsdb.NewOvsSet([]int{})
+	emptyStrings, _ := libovsdb.NewOvsSet([]string{})
+	port := map[string]interface{}{
+		"vlan_mode": emptyStrings,
+		"tag":       emptyInts,
+		"trunks":    emptyInts,
+		"cvlans":    emptyInts,
+	}
+	for column, value := range vlanColumns {
+		port[column] = value
+	}
+	condition := libovsdb.NewCondition("name", "==", portname)
+	operations := []libovsdb.Operation{{
+		Op:    updateOperation,
+		Table: portTableName,
+		Row:   port,
+		Where: []interface{}{condition},
+	}}
+
+	var mutations []interface{}
+	if spec.QinQEthType == "" {
+		keySet, _ := libovsdb.NewOvsSet([]string{"qinq-ethtype"})
+		mutations = append(mutations, libovsdb.NewMutation("other_config", deleteOperation, keySet))
+	} else {
+		mutations = newMapUpdateMutations("other_config", map[string]string{"qinq-ethtype": spec.QinQEthType})
+	}
+	operations = append(operations, libovsdb.Operation{
+		Op:        mutateOperation,
+		Table:     portTableName,
+		Mutations: mutations,
+		Where:     []interface{}{condition},
+	})
+	return client.transact(operations, "update port vlan")
+}
+
+// GetPortVlan returns the VLAN settings of a port
+func (client *ovsClient) GetPortVlan(portname string) (VlanSpec, error) {
+	port, err := client.getPortByName(portname)
+	if err != nil {
+		return VlanSpec{}, err
+	}
+	return VlanSpec{
+		Mode:        port.VlanMode,
+		Tag:         int(port.Tag),
+		HasTag:      port.HasTag,
+		Trunks:      port.Trunks,
+		CVLANs:      port.CVLANs,
+		QinQEthType: port.QinQEthType,
+	}, nil
+}
+
+// tagged tells if the spec sets the tag
+func (spec *VlanSpec) tagged() bool {
+	return spec.HasTag || spec.Tag != 0
+}
+
+// validate checks the VLAN ids and that the settings suit the mode
+func (spec *VlanSpec) validate() error {
+	if err := validateVlanTag(spec.Tag); err != nil {
+		return err
+	}
+	for _, vlans := range [][]int{spec.Trunks, spec.CVLANs} {
+		for _, vlan := range vlans {
+			if err := validateVlanTag(vlan); err != nil {
+				return err
+			}
+		}
+	}
+	switch spec.Mode {
+	case "":
+	case VlanModeAccess:
+		if len(spec.Trunks) != 0 {
+			return fmt.Errorf("The %s vlan mode doesn't allow trunks", spec.Mode)
+		}
+	case VlanModeTrunk:
+		if spec.tagged() {
+			return fmt.Errorf("The %s vlan mode doesn't allow a tag", spec.Mode)
+		}
+	case VlanModeNativeTagged, VlanModeNativeUntagged, VlanModeDot1qTunnel:
+		if !spec.tagged() {
+			return fmt.Errorf("The %s vlan mode requires a tag", spec.Mode)
+		}
+	default:
+		return fmt.Errorf("The vlan mode %s is invalid", spec.Mode)
+	}
+	if spec.Mode != VlanModeDot1qTunnel && (len(spec.CVLANs) != 0 || spec.QinQEthType != "") {
+		return fmt.Errorf("The cvlans and qinq-ethtype are only allowed with the %s vlan mode", VlanModeDot1qTunnel)
+	}
+	switch spec.QinQEthType {
+	case "", QinQEthType8021ad, QinQEthType8021q:
+	default:
+		return fmt.Errorf("The qinq-ethtype %s is invalid, it should be %s or %s", spec.QinQEthType, QinQEthType8021ad, QinQEthType8021q)
+	}
+	return nil
+}
+
+// portColumns validates the spec and returns the Port columns to set, the
+// qinq-ethtype is left out since it belongs to other_config
+func (spec *VlanSpec) portColumns() (map[string]interface{}, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	columns := make(map[string]interface{})
+	if spec.Mode != "" {
+		columns["vlan_mode"] = spec.Mode
+	}
+	if spec.tagged() {
+		columns["tag"] = spec.Tag
+	}
+	if len(spec.Trunks) != 0 {
+		columns["trunks"], _ = libovsdb.NewOvsSet(spec.Trunks)
+	}
+	if len(spec.CVLANs) != 0 {
+		columns["cvlans"], _ = libovsdb.NewOvsSet(spec.CVLANs)
+	}
+	return columns, nil
+}
@@ -0,0 +1,80 @@
+package goovs
+
+import (
+	"testing"
+
+	"github.com/rocksolidlabs/libovsdb"
+)
+
+func TestVlanSpecPortColumns(t *testing.T) {
+	spec := VlanSpec{Mode: VlanModeNativeUntagged, Tag: 10, Trunks: []int{10, 20, 30}}
+	columns, err := spec.portColumns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns["vlan_mode"] != VlanModeNativeUntagged || columns["tag"] != 10 {
+		t.Fatalf("The port columns %v are incorrect", columns)
+	}
+	if trunks, ok := columns["trunks"].(*libovsdb.OvsSet); !ok || len(trunks.GoSet) != 3 {
+		t.Fatalf("The trunks %v are incorrect", columns["trunks"])
+	}
+	invalid := []VlanSpec{
+		{Mode: "hybrid"},
+		{Mode: VlanModeAccess, Tag: 10, Trunks: []int{20}},
+		{Mode: VlanModeTrunk, Tag: 10},
+		{Mode: VlanModeTrunk, HasTag: true},
+		{Mode: VlanModeNativeTagged, Trunks: []int{20}},
+		{Mode: VlanModeDot1qTunnel},
+		{Mode: VlanModeTrunk, Trunks: []int{4096}},
+		{Mode: VlanModeAccess, Tag: 10, CVLANs: []int{100}},
+		{Mode: VlanModeDot1qTunnel, Tag: 10, QinQEthType: "0x9100"},
+	}
+	for _, spec := range invalid {
+		if _, err = spec.portColumns(); err == nil {
+			t.Fatalf("The vlan spec %+v should be invalid", spec)
+		}
+	}
+}
+
+func TestVlanSpecTagZero(t *testing.T) {
+	untagged := VlanSpec{Mode: VlanModeAccess}
+	if columns, err := untagged.portColumns(); err != nil {
+		t.Fatal(err)
+	} else if _, ok := columns["tag"]; ok {
+		t.Fatalf("The untagged spec should not set the tag, got %v", columns)
+	}
+	tagged := VlanSpec{Mode: VlanModeNativeTagged, HasTag: true}
+	if columns, err := tagged.portColumns(); err != nil {
+		t.Fatal(err)
+	} else if tag, ok := columns["tag"]; !ok || tag != 0 {
+		t.Fatalf("The vlan 0 should be set as the tag, got %v", columns)
+	}
+}
+
+func TestGetPortVlan(t *testing.T) {
+	testClient := &ovsClient{portCache: map[string]*OvsPort{
+		"p0": {UUID: "p0", Name: "vm0", HasTag: true},
+		"p1": {UUID: "p1", Name: "vm1"},
+	}}
+	if spec, err := testClient.GetPortVlan("vm0"); err != nil || !spec.HasTag || spec.Tag != 0 {
+		t.Fatalf("The port vm0 should have the vlan 0 as tag, got %+v, %v", spec, err)
+	}
+	if spec, err := testClient.GetPortVlan("vm1"); err != nil || spec.HasTag {
+		t.Fatalf("The port vm1 should be untagged, got %+v, %v", spec, err)
+	}
+}
+
+func TestPortSpecVlanSpec(t *testing.T) {
+	spec := PortSpec{Name: "patch0", Type: "patch", PeerName: "patch1", VlanTag: 10, VlanMode: VlanModeAccess}
+	if vlan := spec.vlanSpec(); vlan.Tag != 0 || vlan.Mode != "" {
+		t.Fatalf("The patch port should have no vlan settings, got %+v", vlan)
+	}
+}
+
+func TestCreateTrunkPort(t *testing.T) {
+	// TODO
+}
+
+func TestUpdatePortVlan(t *testing.T) {
+	// TODO
+}